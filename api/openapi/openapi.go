@@ -0,0 +1,28 @@
+// Package openapi holds the OpenAPI 3.1 document of the REST API. It is the
+// source of truth: requests are validated against it, /openapi.yaml serves it
+// and pkg/client is generated from it.
+package openapi
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.yaml
+var Spec []byte
+
+// Load parses and validates the document.
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("parse openapi document: %w", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi document: %w", err)
+	}
+	return doc, nil
+}
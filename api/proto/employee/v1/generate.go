@@ -0,0 +1,6 @@
+// Package employeev1 holds the generated code of employee.proto. Run
+// go generate after changing it; protoc, protoc-gen-go and
+// protoc-gen-go-grpc must be on PATH.
+package employeev1
+
+//go:generate protoc -I ../../../.. --go_out=../../../.. --go_opt=paths=source_relative --go-grpc_out=../../../.. --go-grpc_opt=paths=source_relative api/proto/employee/v1/employee.proto
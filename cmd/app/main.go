@@ -1,89 +1,270 @@
 // Package main provides the main entry point for the  CRUD API
-//
-//	@title						CRUD API
-//	@version					1.2.0
-//	@description				CRUD Api for employees
-//	@termsOfService				http://swagger.io/terms/
-//
-//	@contact.name				API Support
-//	@contact.url				http://www.swagger.io/support
-//	@contact.email				support@swagger.io
-//
-//	@license.name				Apache 2.0
-//	@license.url				http://www.apache.org/licenses/LICENSE-2.0.html
-//
-//	@host						localhost:8080
-//	@BasePath					/api/v1
-//
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/MaulanaAhmadSulami/juke_test.git/api/openapi"
+	"github.com/MaulanaAhmadSulami/juke_test.git/cmd/migrate/migrations"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/db"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/health"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/metrics"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/migrate"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/cached"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/instrumented"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	attachmentRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/attachment"
+	attributeRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/attribute"
+	changeRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/change"
 	employeeRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/employee"
-	employeeService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/employee"
+	leaveRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/leave"
+	outboxRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/outbox"
+	timeEntryRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/timeentry"
+	webhookRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/webhook"
+	grpcServer "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/grpc"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/compression"
+	adminHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/admin"
+	attachmentHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/attachment"
+	attributeHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/attribute"
+	docsHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/docs"
 	employeeHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/employee"
+	graphqlHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/graphql"
+	leaveHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/leave"
+	timeEntryHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/timeentry"
+	webhookHandler "github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/handler/webhook"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/validation"
+	attachmentService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/attachment"
+	attributeService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/attribute"
+	employeeService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/employee"
+	instrumentedService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/instrumented"
+	leaveService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/leave"
+	timeEntryService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/timeentry"
+	webhookService "github.com/MaulanaAhmadSulami/juke_test.git/internal/service/webhook"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/storage/blob"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/tracing"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"go.uber.org/zap"
-
-	_ "github.com/MaulanaAhmadSulami/juke_test.git/docs"
-	httpSwagger "github.com/swaggo/http-swagger"
 )
 
 const VERSION = "1.1.4"
 
 func main() {
-	logger, err := zap.NewProduction()
+	runMigrations := flag.Bool("migrate", false, "apply pending database migrations before starting")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.Load(flag.CommandLine)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger, err := logging.New(cfg.Logging.Level, cfg.Logging.Format)
 	if err != nil {
 		log.Fatal("Failed to create logger:", err)
 	}
 	defer logger.Sync()
-	sugar := logger.Sugar()
+	sugar := logger.SugaredLogger
 
 	sugar.Infow("start api", "version", VERSION)
 
-	cfg, err := config.Load()
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, VERSION)
 	if err != nil {
-		sugar.Fatalw("Failed to load config", "error", err)
+		sugar.Fatalw("failed to set up tracing", "error", err)
 	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			sugar.Errorw("failed to flush traces", "error", err)
+		}
+	}()
 
-	database, err := db.NewPostgresDB(cfg.GetDBConnectionString(), cfg.DB)
+	database, err := db.NewPostgresDB(cfg.DSN, cfg.DB, sugar)
 	if err != nil {
 		sugar.Fatalw("feailed to connect to db", err)
 	}
 	defer database.Close()
 
+	repository.QueryTimeoutDuration = cfg.DB.QueryTimeout
+
 	sugar.Info("db connected")
 
+	dbMonitor := db.NewMonitor(database, cfg.DB.PingInterval)
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	go dbMonitor.Run(monitorCtx)
+
+	migrator, err := migrate.New(database, migrations.FS)
+	if err != nil {
+		sugar.Fatalw("failed to load migrations", "error", err)
+	}
+
+	if *runMigrations {
+		ran, err := migrator.Up(context.Background(), 0)
+		for _, m := range ran {
+			sugar.Infow("migrated", "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			sugar.Fatalw("failed to migrate", "error", err)
+		}
+	}
+
+	blobs, err := blob.NewFilesystemStore(cfg.Attachments.Dir)
+	if err != nil {
+		sugar.Fatalw("failed to open attachment storage", "error", err)
+	}
+
+	if len(cfg.APITokens) == 0 {
+		sugar.Warn("API_TOKENS is empty, authenticated routes will reject every request")
+	}
+
+	appMetrics := metrics.New(VERSION, database)
+
+	empRepo := instrumented.NewEmployeeRepository(employeeRepo.NewEmployeeStore(database), appMetrics)
+	var attrRepo repository.AttributeRepository = attributeRepo.NewAttributeStore(database)
+	if cfg.Employees.CacheSize > 0 {
+		cachedEmpRepo := cached.NewEmployeeRepository(empRepo, cached.NewLRU(cfg.Employees.CacheSize, cfg.Employees.CacheTTL), appMetrics)
+		empRepo = cachedEmpRepo
+		attrRepo = cached.NewAttributeRepository(attrRepo, cachedEmpRepo)
+	}
+	empService := instrumentedService.NewEmployeesService(employeeService.NewEmployeeService(empRepo, attrRepo, blobs, sugar))
+	attrService := attributeService.NewAttributeService(attrRepo)
+	lvRepo := leaveRepo.NewLeaveStore(database)
+	lvService := leaveService.NewLeaveService(lvRepo, empRepo)
+	teRepo := timeEntryRepo.NewTimeEntryStore(database)
+	teService := timeEntryService.NewTimeEntryService(teRepo, empRepo, cfg.Attendance)
+	attRepo := attachmentRepo.NewAttachmentStore(database)
+	attService := attachmentService.NewAttachmentService(attRepo, empRepo, blobs, cfg.Attachments, sugar)
+	whRepo := webhookRepo.NewWebhookStore(database)
+	deliverer := events.NewDeliverer(whRepo, cfg.Webhooks, sugar)
+	whService := webhookService.NewWebhookService(whRepo, cfg.Webhooks)
+
+	sinks, closeSinks, err := events.NewSinks(cfg.Outbox)
+	if err != nil {
+		sugar.Fatalw("failed to set up event sinks", "error", err)
+	}
+	defer closeSinks()
+	// Webhook subscriptions are fed from the outbox like any other sink.
+	sinks = append(sinks, events.NewSubscriptionSink(whRepo))
+
+	dispatcher := events.NewDispatcher(database, outboxRepo.NewOutboxStore(database), sinks, cfg.Outbox, sugar)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	var workers sync.WaitGroup
+	workers.Go(func() { dispatcher.Run(workerCtx) })
+	workers.Go(func() { deliverer.Run(workerCtx) })
 
-	empRepo := employeeRepo.NewEmployeeStore(database)
-	empService := employeeService.NewEmployeeService(empRepo)
+	feed := events.NewFeed(changeRepo.NewChangeStore(database), cfg.DSN, cfg.Stream, sugar)
+	workers.Go(func() { feed.Run(workerCtx) })
+
+	apiDoc, err := openapi.Load()
+	if err != nil {
+		sugar.Fatalw("failed to load openapi document", "error", err)
+	}
+	validator, err := validation.Middleware(apiDoc, cfg.OpenAPI, sugar)
+	if err != nil {
+		sugar.Fatalw("failed to set up request validation", "error", err)
+	}
+	if cfg.OpenAPI.ValidateResponses {
+		sugar.Warn("OPENAPI_VALIDATE_RESPONSES is on, responses are buffered and checked against the OpenAPI document")
+	}
+
+	compress, err := compression.Middleware(cfg.Compression)
+	if err != nil {
+		sugar.Fatalw("failed to set up response compression", "error", err)
+	}
 
 	router := chi.NewRouter()
 
 	// Middleware
-	router.Use(middleware.Logger)
-	router.Use(middleware.Recoverer)
 	router.Use(middleware.RequestID)
+	router.Use(tracing.Middleware)
+	router.Use(appMetrics.Middleware)
+	router.Use(logging.Middleware(sugar))
+	router.Use(middleware.Recoverer)
+	// Outside the validator, which checks answers before they are compressed.
+	router.Use(compress)
+	// The validator runs in every route group after authentication, so a
+	// caller without a valid token gets the 401 rather than details about
+	// the request they may not make.
+	validate := func(next http.Handler) http.Handler { return next }
+	if cfg.OpenAPI.ValidateRequests || cfg.OpenAPI.ValidateResponses {
+		validate = validator
+	}
+
+	// Liveness has no dependency checks: a live process that cannot reach
+	// Postgres should be taken out of rotation, not restarted.
+	liveness := health.NewRegistry(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	readiness := health.NewRegistry(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+	// The monitor already pings in the background, probes answer with its
+	// last result.
+	readiness.Register("database", dbMonitor)
+	readiness.Register("migrations", health.Migrations(migrator))
+	readiness.Register("attachment_disk", health.DiskSpace(cfg.Attachments.Dir, uint64(cfg.Health.MinFreeBytes)))
 
-	router.Get("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"OK","version":"` + VERSION + `"}`))
+	router.Get("/livez", liveness.Handler())
+	router.Get("/readyz", readiness.Handler())
+	router.Get("/health", readiness.Handler())
+
+	// Pool statistics and the result of the last background ping.
+	router.Get("/debug/db", func(w http.ResponseWriter, r *http.Request) {
+		status := dbMonitor.Status()
+		code := http.StatusOK
+		if !status.Ready {
+			code = http.StatusServiceUnavailable
+		}
+		protocol.WriteJSON(w, code, status)
 	})
 
-	router.Get("/swagger/*", httpSwagger.WrapHandler)
-	router.Route("/api/v1/employees", employeeHandler.RegisterRoute(empService, sugar))
+	router.Method(http.MethodGet, "/metrics", appMetrics.Handler())
+	docsRoutes, err := docsHandler.RegisterRoute(apiDoc)
+	if err != nil {
+		sugar.Fatalw("failed to serve openapi document", "error", err)
+	}
+	router.Group(docsRoutes)
+	graphqlRoutes, err := graphqlHandler.RegisterRoute(empService, cfg.GraphQL, cfg.Employees, sugar)
+	if err != nil {
+		sugar.Fatalw("failed to build graphql schema", "error", err)
+	}
+	router.With(auth.Identify(cfg.APITokens, cfg.APIRoles)).Group(graphqlRoutes)
+	router.With(auth.Identify(cfg.APITokens, cfg.APIRoles), validate).
+		Route("/api/v1/employees", employeeHandler.RegisterRoute(empService, feed, cfg.Stream.Heartbeat, cfg.Employees, sugar))
+	router.With(auth.RequireToken(cfg.APITokens), auth.RequireRole(cfg.APIRoles, auth.RoleAdmin), validate).
+		Route("/api/v1/attribute-definitions", attributeHandler.RegisterRoute(attrService, sugar))
+	router.With(auth.RequireToken(cfg.APITokens), validate).
+		Group(leaveHandler.RegisterRoute(lvService, cfg.APIEmployees, sugar))
+	router.With(auth.RequireToken(cfg.APITokens), validate).
+		Group(timeEntryHandler.RegisterRoute(teService, sugar))
+	router.With(auth.RequireToken(cfg.APITokens), validate).
+		Group(attachmentHandler.RegisterRoute(attService, cfg.Attachments.MaxBytes, sugar))
+	router.With(auth.RequireToken(cfg.APITokens), validate).
+		Route("/api/v1/admin", adminHandler.RegisterRoute(logger, cfg.APIRoles))
+	router.With(auth.RequireToken(cfg.APITokens), validate).
+		Route("/api/v1/webhooks", webhookHandler.RegisterRoute(whService, sugar))
 
 	sugar.Info("Routes registered")
 
@@ -91,10 +272,13 @@ func main() {
 	srv := &http.Server{
 		Addr:         addr,
 		Handler:      router,
-		ReadTimeout:  10 * time.Second,
-		WriteTimeout: 10 * time.Second,
-		IdleTimeout:  time.Minute,
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout,
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
 	}
+	// Event streams only end when their client leaves, close them so
+	// Shutdown can drain.
+	srv.RegisterOnShutdown(feed.Close)
 
 	go func() {
 		sugar.Infow("Server started", "address", addr)
@@ -103,18 +287,43 @@ func main() {
 		}
 	}()
 
+	grpcAddr := fmt.Sprintf(":%s", cfg.GRPC.Port)
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		sugar.Fatalw("failed to listen for grpc", "address", grpcAddr, "error", err)
+	}
+	grpcSrv := grpcServer.NewServer(empService, feed, cfg.GRPC, cfg.Employees, cfg.APITokens, cfg.APIRoles, sugar)
+	go func() {
+		sugar.Infow("grpc server started", "address", grpcAddr)
+		if err := grpcSrv.Serve(grpcListener); err != nil {
+			sugar.Fatalw("grpc server failed", "error", err)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	sugar.Info("shutdown server")
+	readiness.Shutdown()
+	time.Sleep(cfg.HTTP.ShutdownDelay)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.HTTP.ShutdownTimeout)
 	defer cancel()
 
+	var servers sync.WaitGroup
+	servers.Go(func() {
+		if err := grpcSrv.Shutdown(ctx); err != nil {
+			sugar.Errorw("grpc server forced shutdown", "error", err)
+		}
+	})
 	if err := srv.Shutdown(ctx); err != nil {
 		sugar.Fatalw("server forced shutdown", "error", err)
 	}
+	servers.Wait()
+
+	stopWorkers()
+	workers.Wait()
 
 	sugar.Info("server stop")
-}
\ No newline at end of file
+}
@@ -4,17 +4,18 @@ import (
 	"log"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/db"
+	"go.uber.org/zap"
 )
 
 func testConnection(){
-	cfg, err := config.Load()
+	cfg, err := config.Load(nil)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	log.Println("trying to connect with:", cfg.GetDBConnectionString())
+	log.Println("trying to connect with:", cfg.RedactedDSN())
 
-	database, err := db.NewPostgresDB(cfg.GetDBConnectionString(), cfg.DB)
+	database, err := db.NewPostgresDB(cfg.DSN, cfg.DB, zap.NewExample().Sugar())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/pkg/client"
+)
+
+type command struct {
+	api    *client.Client
+	format format
+	out    io.Writer
+}
+
+// attrFlag collects repeated -attr name=value flags.
+type attrFlag map[string]string
+
+func (a attrFlag) String() string { return "" }
+
+func (a attrFlag) Set(s string) error {
+	name, value, ok := strings.Cut(s, "=")
+	if !ok || name == "" {
+		return fmt.Errorf("%q is not name=value", s)
+	}
+	a[name] = value
+	return nil
+}
+
+// employeeFlags are the per-field flags of create and update.
+type employeeFlags struct {
+	file       string
+	name       string
+	email      string
+	position   string
+	salary     float64
+	manager    int64
+	attributes attrFlag
+}
+
+func newFlagSet(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: employeectl %s %s\n\nflags:\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+func (f *employeeFlags) register(fs *flag.FlagSet) {
+	f.attributes = attrFlag{}
+	fs.StringVar(&f.file, "f", "", "JSON or YAML file with the employee, - for stdin")
+	fs.StringVar(&f.name, "name", "", "name")
+	fs.StringVar(&f.email, "email", "", "email")
+	fs.StringVar(&f.position, "position", "", "position")
+	fs.Float64Var(&f.salary, "salary", 0, "salary")
+	fs.Int64Var(&f.manager, "manager", 0, "manager ID, 0 removes the manager")
+	fs.Var(f.attributes, "attr", "custom attribute as name=value, repeatable; values are parsed as JSON when possible")
+}
+
+// apply copies the flags that were set onto emp.
+func (f *employeeFlags) apply(fs *flag.FlagSet, emp *client.Employee) {
+	fs.Visit(func(fl *flag.Flag) {
+		switch fl.Name {
+		case "name":
+			emp.Name = f.name
+		case "email":
+			emp.Email = f.email
+		case "position":
+			emp.Position = f.position
+		case "salary":
+			emp.Salary = f.salary
+		case "manager":
+			emp.ManagerID = nil
+			if f.manager != 0 {
+				id := f.manager
+				emp.ManagerID = &id
+			}
+		}
+	})
+	for name, raw := range f.attributes {
+		if emp.Attributes == nil {
+			emp.Attributes = map[string]any{}
+		}
+		var value any
+		if json.Unmarshal([]byte(raw), &value) != nil {
+			value = raw
+		}
+		emp.Attributes[name] = value
+	}
+}
+
+func parseArgs(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+// parseID reads the ID argument; flags may follow it.
+func parseID(fs *flag.FlagSet, args []string) (int64, error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		if err := parseArgs(fs, args); err != nil {
+			return 0, err
+		}
+		fs.Usage()
+		return 0, errUsage
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id <= 0 {
+		fmt.Fprintf(os.Stderr, "%q is not an employee ID\n", args[0])
+		return 0, errUsage
+	}
+	return id, parseArgs(fs, args[1:])
+}
+
+func (c *command) list(ctx context.Context, args []string) error {
+	fs := newFlagSet("list", "[-attr name=value]...")
+	attrs := attrFlag{}
+	fs.Var(attrs, "attr", "only employees with this custom attribute value, repeatable")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	emps, err := c.api.ListEmployees(ctx, &client.ListEmployeesParams{Attr: attrs})
+	if err != nil {
+		return err
+	}
+	return write(c.out, c.format, emps)
+}
+
+func (c *command) get(ctx context.Context, args []string) error {
+	id, err := parseID(newFlagSet("get", "ID"), args)
+	if err != nil {
+		return err
+	}
+
+	emp, err := c.api.GetEmployee(ctx, id, nil)
+	if err != nil {
+		return err
+	}
+	return write(c.out, c.format, emp)
+}
+
+func (c *command) create(ctx context.Context, args []string) error {
+	fs := newFlagSet("create", "[-f FILE | -name NAME -email EMAIL -salary SALARY ...]")
+	var fields employeeFlags
+	fields.register(fs)
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	var emp client.Employee
+	if fields.file != "" {
+		emps, err := readEmployees(fields.file)
+		if err != nil {
+			return err
+		}
+		if len(emps) != 1 {
+			return fmt.Errorf("%s holds %d employees, use import for more than one", fields.file, len(emps))
+		}
+		emp = emps[0]
+	}
+	fields.apply(fs, &emp)
+
+	created, err := c.api.CreateEmployee(ctx, emp)
+	if err != nil {
+		return err
+	}
+	return write(c.out, c.format, created)
+}
+
+// update sends the whole employee, as the API replaces every field. With
+// field flags the current employee is fetched first so only the flags
+// change.
+func (c *command) update(ctx context.Context, args []string) error {
+	fs := newFlagSet("update", "ID [-f FILE | -name NAME -salary SALARY ...]")
+	var fields employeeFlags
+	fields.register(fs)
+	id, err := parseID(fs, args)
+	if err != nil {
+		return err
+	}
+
+	var emp *client.Employee
+	if fields.file != "" {
+		emps, err := readEmployees(fields.file)
+		if err != nil {
+			return err
+		}
+		if len(emps) != 1 {
+			return fmt.Errorf("%s holds %d employees, expected one", fields.file, len(emps))
+		}
+		emp = &emps[0]
+	} else if emp, err = c.api.GetEmployee(ctx, id, nil); err != nil {
+		return err
+	}
+	fields.apply(fs, emp)
+	emp.ID = id
+
+	updated, err := c.api.UpdateEmployee(ctx, id, *emp)
+	if err != nil {
+		return err
+	}
+	return write(c.out, c.format, updated)
+}
+
+// delete removes every given employee and fails with the error of the last
+// one that could not be deleted.
+func (c *command) delete(ctx context.Context, args []string) error {
+	fs := newFlagSet("delete", "ID...")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	var ids []int64
+	for _, arg := range fs.Args() {
+		id, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || id <= 0 {
+			fmt.Fprintf(os.Stderr, "%q is not an employee ID\n", arg)
+			return errUsage
+		}
+		ids = append(ids, id)
+	}
+
+	var (
+		failed  int
+		lastErr error
+	)
+	for _, id := range ids {
+		if err := c.api.DeleteEmployee(ctx, id); err != nil {
+			fmt.Fprintf(os.Stderr, "employee %d: %v\n", id, err)
+			failed++
+			lastErr = err
+			continue
+		}
+		fmt.Fprintf(c.out, "deleted employee %d\n", id)
+	}
+	if lastErr != nil {
+		return fmt.Errorf("%d of %d employees were not deleted: %w", failed, len(ids), lastErr)
+	}
+	return nil
+}
+
+func (c *command) export(ctx context.Context, args []string) error {
+	fs := newFlagSet("export", "[-file FILE] [-attr name=value]...")
+	file := fs.String("file", "-", "file to write, - for stdout")
+	attrs := attrFlag{}
+	fs.Var(attrs, "attr", "only employees with this custom attribute value, repeatable")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+
+	// Tables cannot be imported again.
+	f := c.format
+	if f == formatTable {
+		f = formatJSON
+	}
+
+	emps, err := c.api.ListEmployees(ctx, &client.ListEmployeesParams{Attr: attrs})
+	if err != nil {
+		return err
+	}
+	if emps == nil {
+		emps = []client.Employee{}
+	}
+
+	if *file == "-" {
+		return write(c.out, f, emps)
+	}
+	out, err := os.Create(*file)
+	if err != nil {
+		return err
+	}
+	if err := write(out, f, emps); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "exported %d employees to %s\n", len(emps), *file)
+	return nil
+}
+
+// importFile creates the employees of a file one by one. IDs and creation
+// times in the file are ignored, manager IDs are sent as they are.
+func (c *command) importFile(ctx context.Context, args []string) error {
+	fs := newFlagSet("import", "FILE")
+	stopOnError := fs.Bool("stop-on-error", false, "stop at the first employee that cannot be created")
+	if err := parseArgs(fs, args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	emps, err := readEmployees(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	var (
+		created int
+		lastErr error
+	)
+	for i, emp := range emps {
+		emp.ID = 0
+		if _, err := c.api.CreateEmployee(ctx, emp); err != nil {
+			fmt.Fprintf(os.Stderr, "employee %d (%s): %v\n", i+1, emp.Email, err)
+			lastErr = err
+			if *stopOnError || ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		created++
+	}
+
+	fmt.Fprintf(os.Stderr, "imported %d of %d employees\n", created, len(emps))
+	if lastErr != nil {
+		return fmt.Errorf("%d employees were not imported: %w", len(emps)-created, lastErr)
+	}
+	return nil
+}
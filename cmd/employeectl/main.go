@@ -0,0 +1,199 @@
+// Command employeectl manages employees through the REST API.
+//
+// Global flags go before the command:
+//
+//	employeectl list -attr cost_center=CC-100
+//	employeectl -o yaml get 42
+//	employeectl create -name "Jane Doe" -email jane@example.com -salary 90000
+//	employeectl update 42 -position "Staff Engineer"
+//	employeectl delete 42
+//	employeectl export -file employees.json
+//	employeectl import employees.yaml
+//
+// The API URL and token come from a profile in the config file, see
+// profile.go. The exit code tells what went wrong, see exitCode.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/pkg/client"
+)
+
+const usage = `usage: employeectl [flags] <command> [args]
+
+commands:
+  list [-attr name=value]...      list employees
+  get ID                          show one employee
+  create [-f FILE | field flags]  create an employee
+  update ID [-f FILE | field flags]
+                                  change an employee, flags only replace what they set
+  delete ID...                    delete employees
+  export [-file FILE] [-attr name=value]...
+                                  write every employee as JSON or YAML (-o)
+  import FILE                     create the employees in a JSON or YAML file, - for stdin
+
+run employeectl <command> -h for the flags of a command
+
+exit codes:
+  0 success, 1 other error, 2 usage, 3 not found, 4 invalid input,
+  5 conflict, 6 unauthorized or forbidden, 7 server error or unreachable API`
+
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitNotFound
+	exitInvalid
+	exitConflict
+	exitAuth
+	exitServer
+)
+
+// errUsage reports a command line mistake; the usage was already printed.
+var errUsage = errors.New("usage error")
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	flags := flag.NewFlagSet("employeectl", flag.ContinueOnError)
+	configPath := flags.String("config", defaultConfigPath(), "config file with the profiles")
+	profileName := flags.String("profile", "", "profile to use instead of the current one (env EMPLOYEECTL_PROFILE)")
+	url := flags.String("url", "", "API URL, overrides the profile (env EMPLOYEECTL_URL)")
+	token := flags.String("token", "", "bearer token, overrides the profile (env EMPLOYEECTL_TOKEN)")
+	output := flags.String("o", "table", "output format: table, json or yaml")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of every API call")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nflags:")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(os.Args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return exitUsage
+	}
+
+	format, err := parseFormat(*output)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	profile, err := loadProfile(*configPath, firstSet(*profileName, os.Getenv("EMPLOYEECTL_PROFILE")))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+	profile.URL = firstSet(*url, os.Getenv("EMPLOYEECTL_URL"), profile.URL)
+	profile.Token = firstSet(*token, os.Getenv("EMPLOYEECTL_TOKEN"), profile.Token)
+	if profile.URL == "" {
+		fmt.Fprintln(os.Stderr, "no API URL: set one in the profile, with -url or EMPLOYEECTL_URL")
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	opts := []client.Option{
+		client.WithHTTPClient(&http.Client{Timeout: *timeout}),
+		client.WithUserAgent("employeectl"),
+	}
+	if profile.Token != "" {
+		opts = append(opts, client.WithAuth(client.BearerToken(profile.Token)))
+	}
+	cmd := &command{
+		api:    client.New(profile.URL, opts...),
+		format: format,
+		out:    os.Stdout,
+	}
+
+	name, args := flags.Arg(0), flags.Args()[1:]
+	var run func(context.Context, []string) error
+	switch name {
+	case "list":
+		run = cmd.list
+	case "get":
+		run = cmd.get
+	case "create":
+		run = cmd.create
+	case "update":
+		run = cmd.update
+	case "delete":
+		run = cmd.delete
+	case "export":
+		run = cmd.export
+	case "import":
+		run = cmd.importFile
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+		flags.Usage()
+		return exitUsage
+	}
+
+	if err := run(ctx, args); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		return exitCode(err)
+	}
+	return exitOK
+}
+
+// exitCode maps API error statuses to the exit codes listed in usage.
+func exitCode(err error) int {
+	var (
+		apiErr *client.APIError
+		urlErr *url.Error
+	)
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.Is(err, errUsage):
+		return exitUsage
+	case errors.As(err, &apiErr):
+		switch {
+		case apiErr.StatusCode == 404:
+			return exitNotFound
+		case apiErr.StatusCode == 409:
+			return exitConflict
+		case apiErr.StatusCode == 401, apiErr.StatusCode == 403:
+			return exitAuth
+		case apiErr.StatusCode >= 500:
+			return exitServer
+		case apiErr.StatusCode >= 400:
+			return exitInvalid
+		}
+		return exitError
+	case errors.As(err, &urlErr):
+		// The API never answered.
+		return exitServer
+	default:
+		return exitError
+	}
+}
+
+func firstSet(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
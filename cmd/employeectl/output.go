@@ -0,0 +1,125 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/pkg/client"
+	"go.yaml.in/yaml/v3"
+)
+
+type format string
+
+const (
+	formatTable format = "table"
+	formatJSON  format = "json"
+	formatYAML  format = "yaml"
+)
+
+func parseFormat(s string) (format, error) {
+	switch f := format(s); f {
+	case formatTable, formatJSON, formatYAML:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown output format %q, expected table, json or yaml", s)
+}
+
+// write prints an employee or a list of them. YAML uses the JSON field
+// names, so both can be imported again.
+func write(w io.Writer, f format, v any) error {
+	switch f {
+	case formatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+
+	case formatYAML:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+
+	var emps []client.Employee
+	switch v := v.(type) {
+	case []client.Employee:
+		emps = v
+	case *client.Employee:
+		emps = []client.Employee{*v}
+	default:
+		return fmt.Errorf("cannot print %T as a table", v)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tEMAIL\tPOSITION\tSALARY\tMANAGER\tCREATED AT")
+	for _, emp := range emps {
+		manager := ""
+		if emp.ManagerID != nil {
+			manager = strconv.FormatInt(*emp.ManagerID, 10)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			emp.ID, emp.Name, emp.Email, emp.Position,
+			strconv.FormatFloat(emp.Salary, 'f', -1, 64), manager,
+			emp.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+// readEmployees reads one employee or a list of them from a JSON or YAML
+// file, - reads stdin. The format follows the extension; stdin and unknown
+// extensions are tried as JSON, then YAML.
+func readEmployees(path string) ([]client.Employee, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML is decoded generically and re-encoded as JSON so both formats
+	// use the same field names.
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".json" && !json.Valid(data) {
+		var generic any
+		if err := yaml.Unmarshal(data, &generic); err != nil {
+			return nil, fmt.Errorf("%s is neither JSON nor YAML: %w", path, err)
+		}
+		if data, err = json.Marshal(generic); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var emps []client.Employee
+	if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+		err = json.Unmarshal(data, &emps)
+	} else {
+		var emp client.Employee
+		err = json.Unmarshal(data, &emp)
+		emps = append(emps, emp)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read employees from %s: %w", path, err)
+	}
+	return emps, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// profile is where the API is and how to authenticate, as stored in the
+// config file:
+//
+//	current: local
+//	profiles:
+//	  local:
+//	    url: http://localhost:8080
+//	    token: change-me
+//	  production:
+//	    url: https://employees.example.com
+//	    token_file: ~/.secrets/employees-token
+//
+// token_env names an environment variable holding the token instead.
+type profile struct {
+	URL       string `yaml:"url"`
+	Token     string `yaml:"token"`
+	TokenFile string `yaml:"token_file"`
+	TokenEnv  string `yaml:"token_env"`
+}
+
+type configFile struct {
+	Current  string             `yaml:"current"`
+	Profiles map[string]profile `yaml:"profiles"`
+}
+
+func defaultConfigPath() string {
+	if path := os.Getenv("EMPLOYEECTL_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "employeectl", "config.yaml")
+}
+
+// loadProfile returns the named profile, or the current one when name is
+// empty. A missing config file is fine as long as no profile is asked for,
+// the URL can come from flags or the environment.
+func loadProfile(path, name string) (profile, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) && name == "" {
+		return profile{}, nil
+	}
+	if err != nil {
+		return profile{}, fmt.Errorf("cannot read config: %w", err)
+	}
+
+	var cfg configFile
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return profile{}, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+
+	if name == "" {
+		name = cfg.Current
+	}
+	if name == "" {
+		if len(cfg.Profiles) != 1 {
+			return profile{}, fmt.Errorf("%s has no current profile, pick one with -profile", path)
+		}
+		for n := range cfg.Profiles {
+			name = n
+		}
+	}
+	p, ok := cfg.Profiles[name]
+	if !ok {
+		return profile{}, fmt.Errorf("profile %q is not in %s", name, path)
+	}
+
+	switch {
+	case p.TokenFile != "":
+		data, err := os.ReadFile(expandHome(p.TokenFile))
+		if err != nil {
+			return profile{}, fmt.Errorf("profile %q: cannot read token file: %w", name, err)
+		}
+		p.Token = strings.TrimSpace(string(data))
+	case p.TokenEnv != "":
+		p.Token = os.Getenv(p.TokenEnv)
+	}
+	return p, nil
+}
+
+func expandHome(path string) string {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, rest)
+}
@@ -0,0 +1,149 @@
+// Command migrate applies the SQL migrations in cmd/migrate/migrations.
+//
+// Config flags (see migrate -h) go before the command.
+//
+//	migrate status        list migrations and whether they are applied
+//	migrate up [N]        apply the next N pending migrations, all by default
+//	migrate down [N]      revert the last N applied migrations, 1 by default
+//	migrate force VERSION mark migrations up to VERSION as applied without running them
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"text/tabwriter"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/cmd/migrate/migrations"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/db"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/migrate"
+	"go.uber.org/zap"
+)
+
+const usage = `usage: migrate [flags] <command>
+
+commands:
+  status          list migrations and whether they are applied
+  up [N]          apply the next N pending migrations (default: all)
+  down [N]        revert the last N applied migrations (default: 1)
+  force VERSION   record migrations up to VERSION as applied without running them`
+
+func main() {
+	config.RegisterFlags(flag.CommandLine)
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, usage)
+		fmt.Fprintln(os.Stderr, "\nflags:")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("Failed to create logger:", err)
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	cfg, err := config.Load(flag.CommandLine)
+	if err != nil {
+		sugar.Fatalw("Failed to load config", "error", err)
+	}
+
+	database, err := db.NewPostgresDB(cfg.DSN, cfg.DB, sugar)
+	if err != nil {
+		sugar.Fatalw("failed to connect to db", "error", err)
+	}
+	defer database.Close()
+
+	migrator, err := migrate.New(database, migrations.FS)
+	if err != nil {
+		sugar.Fatalw("failed to load migrations", "error", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	command, args := flag.Arg(0), flag.Args()[1:]
+	switch command {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			sugar.Fatalw("failed to read migration status", "error", err)
+		}
+		printStatus(statuses)
+
+	case "up", "down":
+		n := 0
+		if len(args) > 0 {
+			if n, err = strconv.Atoi(args[0]); err != nil || n <= 0 {
+				sugar.Fatalw("N must be a positive number", "value", args[0])
+			}
+		}
+
+		run := migrator.Up
+		if command == "down" {
+			run = migrator.Down
+		}
+
+		ran, err := run(ctx, n)
+		for _, m := range ran {
+			sugar.Infow("migrated", "direction", command, "version", m.Version, "name", m.Name)
+		}
+		if err != nil {
+			sugar.Fatalw("migration failed", "error", err)
+		}
+		if len(ran) == 0 {
+			sugar.Info("nothing to migrate")
+		}
+
+	case "force":
+		if len(args) != 1 {
+			sugar.Fatal("force needs a VERSION")
+		}
+		version, err := strconv.ParseInt(args[0], 10, 64)
+		if err != nil || version < 0 {
+			sugar.Fatalw("VERSION must be a migration version", "value", args[0])
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			sugar.Fatalw("force failed", "error", err)
+		}
+		sugar.Infow("forced version", "version", version)
+
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func printStatus(statuses []migrate.Status) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, st := range statuses {
+		state := "pending"
+		switch {
+		case st.Missing:
+			state = "applied, file missing"
+		case st.Modified:
+			state = "applied, modified"
+		case st.Applied:
+			state = "applied"
+		}
+
+		appliedAt := ""
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%06d\t%s\t%s\t%s\n", st.Version, st.Name, state, appliedAt)
+	}
+	w.Flush()
+}
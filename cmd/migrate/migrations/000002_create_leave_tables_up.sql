@@ -0,0 +1,38 @@
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS manager_id bigint
+    CONSTRAINT employees_manager_id_fkey REFERENCES employees(id) ON DELETE SET NULL;
+
+CREATE TABLE IF NOT EXISTS leave_types (
+    id bigserial PRIMARY KEY,
+    name varchar(100) NOT NULL UNIQUE,
+    annual_days numeric(6,2) NOT NULL CHECK (annual_days >= 0),
+    accrual_method varchar(20) NOT NULL DEFAULT 'annual' CHECK (accrual_method IN ('annual', 'monthly')),
+    max_carry_over numeric(6,2) NOT NULL DEFAULT 0 CHECK (max_carry_over >= 0),
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS leave_balances (
+    employee_id bigint NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    leave_type_id bigint NOT NULL REFERENCES leave_types(id) ON DELETE CASCADE,
+    year int NOT NULL,
+    accrued_days numeric(6,2) NOT NULL DEFAULT 0,
+    carried_over_days numeric(6,2) NOT NULL DEFAULT 0,
+    used_days numeric(6,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (employee_id, leave_type_id, year)
+);
+
+CREATE TABLE IF NOT EXISTS leave_requests (
+    id bigserial PRIMARY KEY,
+    employee_id bigint NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    leave_type_id bigint NOT NULL REFERENCES leave_types(id),
+    start_date date NOT NULL,
+    end_date date NOT NULL,
+    days numeric(6,2) NOT NULL,
+    reason text NOT NULL DEFAULT '',
+    status varchar(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by bigint REFERENCES employees(id) ON DELETE SET NULL,
+    decided_at timestamp,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX IF NOT EXISTS leave_requests_employee_dates_idx ON leave_requests (employee_id, start_date, end_date);
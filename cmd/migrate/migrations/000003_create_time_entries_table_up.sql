@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS time_entries (
+    id bigserial PRIMARY KEY,
+    employee_id bigint NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    clock_in timestamptz NOT NULL,
+    clock_out timestamptz,
+    note text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (clock_out IS NULL OR clock_out > clock_in)
+);
+
+CREATE INDEX IF NOT EXISTS time_entries_employee_clock_in_idx ON time_entries (employee_id, clock_in);
+
+-- At most one running entry per employee.
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_open_idx ON time_entries (employee_id) WHERE clock_out IS NULL;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS employees_attributes_idx;
+ALTER TABLE employees DROP COLUMN IF EXISTS attributes;
+DROP TABLE IF EXISTS attribute_definitions;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS attribute_definitions (
+    id bigserial PRIMARY KEY,
+    name varchar(63) NOT NULL UNIQUE,
+    type varchar(20) NOT NULL CHECK (type IN ('string', 'number', 'boolean', 'enum')),
+    required boolean NOT NULL DEFAULT false,
+    enum_values text[] NOT NULL DEFAULT '{}',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS attributes jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS employees_attributes_idx ON employees USING gin (attributes jsonb_path_ops);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS attachments (
+    id bigserial PRIMARY KEY,
+    employee_id bigint NOT NULL CONSTRAINT attachments_employee_id_fkey REFERENCES employees(id) ON DELETE CASCADE,
+    filename varchar(255) NOT NULL,
+    category varchar(20) NOT NULL CHECK (category IN ('contract', 'id_scan', 'photo', 'other')),
+    content_type varchar(255) NOT NULL,
+    size_bytes bigint NOT NULL CHECK (size_bytes >= 0),
+    sha256 char(64) NOT NULL,
+    storage_key text NOT NULL UNIQUE,
+    uploaded_by varchar(255) NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS attachments_employee_id_idx ON attachments (employee_id);
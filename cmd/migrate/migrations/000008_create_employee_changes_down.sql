@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS employees_record_change ON employees;
+DROP FUNCTION IF EXISTS record_employee_change();
+DROP TABLE IF EXISTS employee_changes;
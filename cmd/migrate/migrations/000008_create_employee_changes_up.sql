@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS employee_changes (
+    id bigserial PRIMARY KEY,
+    event_type varchar(100) NOT NULL,
+    employee_id bigint NOT NULL,
+    payload jsonb NOT NULL,
+    created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS employee_changes_created_at_idx ON employee_changes (created_at);
+
+-- Records every row change of employees and wakes the listeners of the
+-- employee_changes channel. The transaction lock serialises writers until
+-- they commit, so ids become visible in order and a reader that remembers
+-- the last id it saw never skips a change. Notifications without payload
+-- are folded into one per transaction, a bulk COPY sends a single one.
+CREATE OR REPLACE FUNCTION record_employee_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(27980790367741299); -- "changes" in ASCII
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES ('employee.deleted', OLD.id, to_jsonb(OLD));
+    ELSE
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES (CASE TG_OP WHEN 'INSERT' THEN 'employee.created' ELSE 'employee.updated' END, NEW.id, to_jsonb(NEW));
+    END IF;
+
+    PERFORM pg_notify('employee_changes', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS employees_record_change ON employees;
+CREATE TRIGGER employees_record_change
+    AFTER INSERT OR UPDATE OR DELETE ON employees
+    FOR EACH ROW EXECUTE FUNCTION record_employee_change();
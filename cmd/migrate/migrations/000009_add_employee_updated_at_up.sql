@@ -0,0 +1,15 @@
+ALTER TABLE employees ADD COLUMN IF NOT EXISTS updated_at timestamp;
+
+-- The backfill changes no data anyone watches, so it must not record an
+-- employee.updated change for every row. Migrations run in a transaction and
+-- ALTER TABLE locks out other writers until it commits, none of their
+-- changes go unrecorded.
+ALTER TABLE employees DISABLE TRIGGER employees_record_change;
+
+UPDATE employees SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE employees ENABLE TRIGGER employees_record_change;
+
+ALTER TABLE employees
+    ALTER COLUMN updated_at SET DEFAULT CURRENT_TIMESTAMP,
+    ALTER COLUMN updated_at SET NOT NULL;
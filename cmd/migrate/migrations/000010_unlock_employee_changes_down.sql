@@ -0,0 +1,16 @@
+CREATE OR REPLACE FUNCTION record_employee_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(27980790367741299); -- "changes" in ASCII
+
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES ('employee.deleted', OLD.id, to_jsonb(OLD));
+    ELSE
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES (CASE TG_OP WHEN 'INSERT' THEN 'employee.created' ELSE 'employee.updated' END, NEW.id, to_jsonb(NEW));
+    END IF;
+
+    PERFORM pg_notify('employee_changes', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
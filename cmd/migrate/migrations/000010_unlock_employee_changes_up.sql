@@ -0,0 +1,17 @@
+-- Writers no longer wait for each other. Change ids can now become visible
+-- out of order; the feed holds back at a gap in the ids until every
+-- transaction that could still commit the missing change has ended.
+CREATE OR REPLACE FUNCTION record_employee_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES ('employee.deleted', OLD.id, to_jsonb(OLD));
+    ELSE
+        INSERT INTO employee_changes (event_type, employee_id, payload)
+        VALUES (CASE TG_OP WHEN 'INSERT' THEN 'employee.created' ELSE 'employee.updated' END, NEW.id, to_jsonb(NEW));
+    END IF;
+
+    PERFORM pg_notify('employee_changes', '');
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
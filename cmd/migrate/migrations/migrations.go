@@ -0,0 +1,8 @@
+// Package migrations embeds the SQL migrations so the app and the migrate
+// command ship them inside their binaries.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
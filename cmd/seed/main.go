@@ -0,0 +1,94 @@
+// Command seed fills the employees table with deterministic synthetic data.
+//
+//	seed -profile demo
+//	seed -profile load-test -truncate
+//	seed -profile small -n 1000 -seed 99
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/db"
+	employeeRepo "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/employee"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/seed"
+	"go.uber.org/zap"
+)
+
+const batchSize = 5000
+
+func main() {
+	profileName := flag.String("profile", "small", "fixture profile: "+strings.Join(seed.ProfileNames(), ", "))
+	count := flag.Int("n", 0, "number of employees, overrides the profile")
+	seedValue := flag.Uint64("seed", 0, "random seed, overrides the profile")
+	truncate := flag.Bool("truncate", false, "delete all employees (and everything referencing them) first")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	profile, ok := seed.Profiles[*profileName]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown profile %q, expected one of %s\n", *profileName, strings.Join(seed.ProfileNames(), ", "))
+		os.Exit(2)
+	}
+	if *count > 0 {
+		profile.Count = *count
+	}
+	if *seedValue > 0 {
+		profile.Seed = *seedValue
+	}
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal("Failed to create logger:", err)
+	}
+	defer logger.Sync()
+	sugar := logger.Sugar()
+
+	cfg, err := config.Load(flag.CommandLine)
+	if err != nil {
+		sugar.Fatalw("Failed to load config", "error", err)
+	}
+
+	database, err := db.NewPostgresDB(cfg.DSN, cfg.DB, sugar)
+	if err != nil {
+		sugar.Fatalw("failed to connect to db", "error", err)
+	}
+	defer database.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *truncate {
+		if _, err := database.ExecContext(ctx, `TRUNCATE employees RESTART IDENTITY CASCADE`); err != nil {
+			sugar.Fatalw("failed to truncate employees", "error", err)
+		}
+		sugar.Info("employees truncated")
+	}
+
+	start := time.Now()
+	employees := seed.Generate(profile, profile.Count, profile.Seed)
+	repo := employeeRepo.NewEmployeeStore(database)
+
+	for offset := 0; offset < len(employees); offset += batchSize {
+		end := min(offset+batchSize, len(employees))
+		if err := repo.CreateMany(ctx, employees[offset:end]); err != nil {
+			sugar.Fatalw("failed to insert employees", "error", err, "inserted", offset)
+		}
+		sugar.Infow("inserted batch", "inserted", end, "total", len(employees))
+	}
+
+	sugar.Infow("seeded employees",
+		"profile", profile.Name,
+		"count", profile.Count,
+		"seed", profile.Seed,
+		"duration", time.Since(start).String(),
+	)
+}
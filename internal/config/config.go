@@ -1,11 +1,23 @@
 package config
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/BurntSushi/toml"
 	"github.com/joho/godotenv"
+	"go.yaml.in/yaml/v3"
 )
 
 type Config struct {
@@ -15,47 +27,587 @@ type Config struct {
 	DBPassword string
 	DBName string
 	DBSSLMode string
+	// DBURL is a DATABASE_URL style connection URL. When set it replaces
+	// the individual DB* fields above.
+	DBURL string
+	// DBURLFile and DBPasswordFile name files holding the URL or password.
+	// They are read again for every new connection so rotated credentials
+	// are picked up without a restart.
+	DBURLFile string
+	DBPasswordFile string
 	ServerPort string
 	DB DbConfig
+	HTTP HTTPConfig
+	Compression CompressionConfig
+	Health HealthConfig
+	Tracing TracingConfig
+	Logging LoggingConfig
+	Outbox OutboxConfig
+	Webhooks WebhookConfig
+	Stream StreamConfig
+	GRPC GRPCConfig
+	GraphQL GraphQLConfig
+	OpenAPI OpenAPIConfig
+	Attendance AttendanceConfig
+	Attachments AttachmentConfig
+	Employees EmployeesConfig
+	// APITokens maps bearer tokens to the name of the caller using them.
+	APITokens map[string]string
+	APITokensFile string
+	// APIRoles maps caller names to their role.
+	APIRoles map[string]string
+	// APIEmployees maps caller names to the employee they act as, e.g. when
+	// approving leave.
+	APIEmployees map[string]int64
 }
 
 type DbConfig struct {
 	MaxOpenConns int
 	MaxIdleConns int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// ConnectTimeout is how long startup keeps retrying an unreachable
+	// database before giving up.
+	ConnectTimeout time.Duration
+	// PingInterval is how often the background health check pings.
+	PingInterval time.Duration
+	// QueryTimeout bounds every repository query.
+	QueryTimeout time.Duration
 }
 
-func Load() (*Config, error){
-	if err := godotenv.Load(".env"); err != nil {
-		log.Println(".env was nowhtere to be found");
+type HTTPConfig struct {
+	ReadTimeout time.Duration
+	WriteTimeout time.Duration
+	IdleTimeout time.Duration
+	ShutdownTimeout time.Duration
+	// ShutdownDelay keeps serving with readiness failing before the
+	// listener closes, giving load balancers time to notice.
+	ShutdownDelay time.Duration
+}
+
+type CompressionConfig struct {
+	// Encodings are the content codings answers may be compressed with, in
+	// order of preference. Empty disables compression.
+	Encodings []string
+	// Answers smaller than MinBytes are sent uncompressed.
+	MinBytes int
+}
+
+type HealthConfig struct {
+	CheckTimeout time.Duration
+	// CacheTTL is how long readiness results are reused between probes.
+	CacheTTL time.Duration
+	// MinFreeBytes is the free space the attachment directory needs for
+	// the instance to be ready.
+	MinFreeBytes int64
+}
+
+type TracingConfig struct {
+	// Exporter is one of none, stdout, file or otlp.
+	Exporter string
+	// Endpoint is the OTLP/HTTP URL; empty uses OTEL_EXPORTER_OTLP_ENDPOINT
+	// or the collector default.
+	Endpoint string
+	File string
+	SampleRatio float64
+	ServiceName string
+}
+
+type LoggingConfig struct {
+	// Level and Format are only the startup values, both can be changed
+	// at runtime through the admin endpoint.
+	Level string
+	Format string
+}
+
+type OutboxConfig struct {
+	// Sinks lists where events go: stdout, file and/or webhook. With no
+	// sinks events are marked published without going anywhere.
+	Sinks []string
+	File string
+	WebhookURL string
+	WebhookTimeout time.Duration
+	PollInterval time.Duration
+	BatchSize int
+	// MaxBackoff caps the delay between retries of a failing event.
+	MaxBackoff time.Duration
+	// Retention is how long published events are kept.
+	Retention time.Duration
+}
+
+type WebhookConfig struct {
+	// Timeout bounds one delivery request.
+	Timeout time.Duration
+	// MaxAttempts is how often a delivery is tried before it is dead.
+	MaxAttempts int
+	// RetryBase is the delay after the first failure, doubling up to
+	// RetryMax after every further one.
+	RetryBase time.Duration
+	RetryMax time.Duration
+	PollInterval time.Duration
+	BatchSize int
+	// Workers is how many deliveries are sent at the same time.
+	Workers int
+	// AllowPrivateNetworks lets subscriptions point at loopback, private
+	// and link-local addresses, for local development.
+	AllowPrivateNetworks bool
+}
+
+type StreamConfig struct {
+	// Heartbeat is how often an idle event stream gets a comment line,
+	// keeping proxies from closing it.
+	Heartbeat time.Duration
+	// ClientBuffer is how many changes a client may fall behind before it
+	// is disconnected; it resumes with Last-Event-ID.
+	ClientBuffer int
+	MaxClients int
+	// PollInterval reads the change log even without a notification, in
+	// case one was lost while the listener reconnected.
+	PollInterval time.Duration
+	// Retention is how long changes are kept for resuming clients.
+	Retention time.Duration
+}
+
+type GRPCConfig struct {
+	Port string
+	// Reflection lets tools like grpcurl discover the services without
+	// the proto files.
+	Reflection bool
+}
+
+type GraphQLConfig struct {
+	// MaxDepth is how deeply fields may be nested in a query.
+	MaxDepth int
+	// MaxComplexity limits the number of fields a query can resolve. List
+	// fields count their selections once per requested item.
+	MaxComplexity int
+	// GraphiQL serves the GraphiQL IDE at /graphiql, meant for
+	// development.
+	GraphiQL bool
+}
+
+type OpenAPIConfig struct {
+	// ValidateRequests rejects requests that do not match api/openapi with
+	// problem details before they reach a handler.
+	ValidateRequests bool
+	// ValidateResponses checks JSON answers against the document too and
+	// replaces mismatches with a 500. Answers are buffered for it, so it is
+	// meant for tests and development.
+	ValidateResponses bool
+}
+
+type AttendanceConfig struct {
+	// Time entries clocked in before LockDate can no longer be created or
+	// adjusted. The zero value disables the lock.
+	LockDate time.Time
+	// Hours worked in a week beyond WeeklyHours count as overtime.
+	WeeklyHours float64
+}
+
+type AttachmentConfig struct {
+	Dir string
+	MaxBytes int64
+	AllowedTypes []string
+}
+
+type EmployeesConfig struct {
+	// RestrictedFields are left out of employee responses unless the
+	// caller's role is one of PrivilegedRoles.
+	RestrictedFields []string
+	PrivilegedRoles []string
+	// CacheMaxAge is the max-age of employee reads. With 0 caches have to
+	// revalidate every time, which conditional requests make cheap.
+	CacheMaxAge time.Duration
+	// CacheSize employees read by ID are kept in process for up to
+	// CacheTTL. A CacheSize of 0 disables the cache.
+	CacheSize int
+	CacheTTL time.Duration
+}
+
+// VisibleFields returns the employee fields a caller with role may read, or
+// nil when that is all of them.
+func (c EmployeesConfig) VisibleFields(role string) []string {
+	if len(c.RestrictedFields) == 0 || slices.Contains(c.PrivilegedRoles, role) {
+		return nil
+	}
+	return slices.DeleteFunc(slices.Clone(employeeEntity.Fields), func(field string) bool {
+		return slices.Contains(c.RestrictedFields, field)
+	})
+}
+
+// RegisterFlags adds --config and one flag per setting (e.g. --db.host) to
+// flags. Pass the same flag set to Load after parsing it.
+func RegisterFlags(flags *flag.FlagSet) {
+	flags.String("config", "", "path to a YAML or TOML config file (env CONFIG_FILE)")
+	for _, s := range settings(&Config{}) {
+		flags.String(s.key, "", fmt.Sprintf("%s (env %s, default %q)", s.usage, s.env, s.def))
+	}
+}
+
+// Load builds the configuration from, in increasing order of precedence,
+// built-in defaults, the config file, environment variables (including a
+// .env file) and command line flags set on flags, which may be nil. Every
+// invalid value is reported, not just the first one.
+func Load(flags *flag.FlagSet) (*Config, error) {
+	if err := godotenv.Load(".env"); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .env: %w", err)
+	}
+
+	config := &Config{}
+	all := settings(config)
+	byKey := make(map[string]*setting, len(all))
+	for i := range all {
+		byKey[all[i].key] = &all[i]
+		if err := all[i].value.Set(all[i].def); err != nil {
+			panic(fmt.Sprintf("config: invalid default for %s: %v", all[i].key, err))
+		}
+	}
+
+	var problems []error
+
+	path := getEnv("CONFIG_FILE", "")
+	if flags != nil {
+		if f := flags.Lookup("config"); f != nil && f.Value.String() != "" {
+			path = f.Value.String()
+		}
+	}
+	if path != "" {
+		values, err := readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(values) {
+			s, ok := byKey[key]
+			if !ok {
+				problems = append(problems, fmt.Errorf("%s: unknown setting in %s", key, path))
+				continue
+			}
+			if err := s.value.Set(values[key]); err != nil {
+				problems = append(problems, fmt.Errorf("%s: %w", key, err))
+			}
+		}
+	}
+
+	for _, s := range all {
+		value, ok := os.LookupEnv(s.env)
+		// Empty variables keep the current value, except for lists: there
+		// empty is a value of its own, COMPRESSION_ENCODINGS= turns
+		// compression off.
+		if _, list := s.value.(*listValue); !ok || (value == "" && !list) {
+			continue
+		}
+		if err := s.value.Set(value); err != nil {
+			problems = append(problems, fmt.Errorf("%s: %w", s.env, err))
+		}
 	}
 
-	config := &Config{
-		DBHost: getEnv("DB_HOST", "localhost"),
-		DBPort: getEnv("DB_PORT", "5433"),
-		DBUser: getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "postgres"),
-		DBName: getEnv("DB_NAME", "employee_db"),
-		DBSSLMode: getEnv("DB_SSLMODE", "disable"),
-		ServerPort: getEnv("SERVER_PORT", "8080"),
-		DB: DbConfig{
-			MaxOpenConns: 25,
-			MaxIdleConns: 5,
-		 },
+	if flags != nil {
+		flags.Visit(func(f *flag.Flag) {
+			if s, ok := byKey[f.Name]; ok {
+				if err := s.value.Set(f.Value.String()); err != nil {
+					problems = append(problems, fmt.Errorf("--%s: %w", f.Name, err))
+				}
+			}
+		})
+	}
+
+	if config.APITokensFile != "" {
+		if tokens, err := readSecret(config.APITokensFile); err != nil {
+			problems = append(problems, fmt.Errorf("auth.api_tokens_file: %w", err))
+		} else if err := (*tokensValue)(&config.APITokens).Set(tokens); err != nil {
+			problems = append(problems, fmt.Errorf("auth.api_tokens_file: %w", err))
+		}
+	}
+
+	problems = append(problems, config.validate()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n%w", errors.Join(problems...))
 	}
 
 	return config, nil
 }
 
-func (c *Config) GetDBConnectionString() string {
-	return fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		c.DBHost, 
-		c.DBPort, 
-		c.DBUser, 
-		c.DBPassword, 
-		c.DBName, 
-		c.DBSSLMode,
-	)
+func (c *Config) validate() []error {
+	var problems []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Errorf(format, args...))
+		}
+	}
+
+	if _, err := c.DSN(); err != nil {
+		problems = append(problems, err)
+	}
+	check(c.DBHost != "", "db.host is required")
+	check(validPort(c.DBPort), "db.port must be a port number, got %q", c.DBPort)
+	check(c.DBUser != "", "db.user is required")
+	check(c.DBName != "", "db.name is required")
+	switch c.DBSSLMode {
+	case "disable", "allow", "prefer", "require", "verify-ca", "verify-full":
+	default:
+		check(false, "db.sslmode %q is not a valid sslmode", c.DBSSLMode)
+	}
+	check(c.DB.MaxOpenConns > 0, "db.max_open_conns must be positive")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns cannot be negative")
+	check(c.DB.MaxIdleConns <= c.DB.MaxOpenConns, "db.max_idle_conns cannot exceed db.max_open_conns")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime cannot be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time cannot be negative")
+	check(c.DB.ConnectTimeout > 0, "db.connect_timeout must be positive")
+	check(c.DB.PingInterval > 0, "db.ping_interval must be positive")
+	check(c.DB.QueryTimeout > 0, "db.query_timeout must be positive")
+
+	check(validPort(c.ServerPort), "server.port must be a port number, got %q", c.ServerPort)
+	check(c.HTTP.ReadTimeout > 0, "server.read_timeout must be positive")
+	check(c.HTTP.WriteTimeout > 0, "server.write_timeout must be positive")
+	check(c.HTTP.IdleTimeout > 0, "server.idle_timeout must be positive")
+	check(c.HTTP.ShutdownTimeout > 0, "server.shutdown_timeout must be positive")
+	check(c.HTTP.ShutdownDelay >= 0, "server.shutdown_delay cannot be negative")
+
+	for _, encoding := range c.Compression.Encodings {
+		check(slices.Contains([]string{"zstd", "br", "gzip"}, encoding), "compression.encodings: unknown encoding %q, expected zstd, br or gzip", encoding)
+	}
+	check(c.Compression.MinBytes >= 0, "compression.min_bytes cannot be negative")
+
+	check(c.Health.CheckTimeout > 0, "health.check_timeout must be positive")
+	check(c.Health.CacheTTL >= 0, "health.cache_ttl cannot be negative")
+	check(c.Health.MinFreeBytes >= 0, "health.min_free_bytes cannot be negative")
+
+	switch c.Tracing.Exporter {
+	case "none", "stdout", "otlp":
+	case "file":
+		check(c.Tracing.File != "", "tracing.file is required with the file exporter")
+	default:
+		check(false, "tracing.exporter must be none, stdout, file or otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "tracing.sample_ratio must be between 0 and 1")
+	check(c.Tracing.ServiceName != "", "tracing.service_name is required")
+
+	switch c.Logging.Level {
+	case "debug", "info", "warn", "error":
+	default:
+		check(false, "logging.level must be debug, info, warn or error, got %q", c.Logging.Level)
+	}
+	check(c.Logging.Format == "json" || c.Logging.Format == "console", "logging.format must be json or console, got %q", c.Logging.Format)
+
+	for _, sink := range c.Outbox.Sinks {
+		switch sink {
+		case "stdout":
+		case "file":
+			check(c.Outbox.File != "", "outbox.file is required with the file sink")
+		case "webhook":
+			check(strings.HasPrefix(c.Outbox.WebhookURL, "http://") || strings.HasPrefix(c.Outbox.WebhookURL, "https://"),
+				"outbox.webhook_url must be an http(s) URL with the webhook sink")
+		default:
+			check(false, "outbox.sinks: unknown sink %q, expected stdout, file or webhook", sink)
+		}
+	}
+	check(c.Outbox.WebhookTimeout > 0, "outbox.webhook_timeout must be positive")
+	check(c.Outbox.PollInterval > 0, "outbox.poll_interval must be positive")
+	check(c.Outbox.BatchSize > 0, "outbox.batch_size must be positive")
+	check(c.Outbox.MaxBackoff >= c.Outbox.PollInterval, "outbox.max_backoff cannot be shorter than outbox.poll_interval")
+	check(c.Outbox.Retention > 0, "outbox.retention must be positive")
+
+	check(c.Webhooks.Timeout > 0, "webhooks.timeout must be positive")
+	check(c.Webhooks.MaxAttempts > 0, "webhooks.max_attempts must be positive")
+	check(c.Webhooks.RetryBase > 0, "webhooks.retry_base must be positive")
+	check(c.Webhooks.RetryMax >= c.Webhooks.RetryBase, "webhooks.retry_max cannot be shorter than webhooks.retry_base")
+	check(c.Webhooks.PollInterval > 0, "webhooks.poll_interval must be positive")
+	check(c.Webhooks.BatchSize > 0, "webhooks.batch_size must be positive")
+	check(c.Webhooks.Workers > 0, "webhooks.workers must be positive")
+
+	check(c.Stream.Heartbeat > 0, "stream.heartbeat must be positive")
+	check(c.Stream.ClientBuffer > 0, "stream.client_buffer must be positive")
+	check(c.Stream.MaxClients > 0, "stream.max_clients must be positive")
+	check(c.Stream.PollInterval > 0, "stream.poll_interval must be positive")
+	check(c.Stream.Retention > 0, "stream.retention must be positive")
+
+	check(validPort(c.GRPC.Port), "grpc.port must be a port number, got %q", c.GRPC.Port)
+	check(c.GRPC.Port != c.ServerPort, "grpc.port must differ from server.port")
+
+	check(c.GraphQL.MaxDepth > 0, "graphql.max_depth must be positive")
+	check(c.GraphQL.MaxComplexity > 0, "graphql.max_complexity must be positive")
+
+	check(c.Attendance.WeeklyHours > 0, "attendance.overtime_weekly_hours must be positive")
+
+	check(c.Attachments.Dir != "", "attachments.dir is required")
+	check(c.Attachments.MaxBytes > 0, "attachments.max_bytes must be positive")
+	check(len(c.Attachments.AllowedTypes) > 0, "attachments.allowed_types needs at least one type")
+
+	for _, field := range c.Employees.RestrictedFields {
+		check(slices.Contains(employeeEntity.Fields, field), "employees.restricted_fields: unknown field %q", field)
+		check(field != "id", "employees.restricted_fields: the id cannot be restricted")
+	}
+	check(c.Employees.CacheMaxAge >= 0, "employees.cache_max_age cannot be negative")
+	check(c.Employees.CacheSize >= 0, "employees.cache_size cannot be negative")
+	check(c.Employees.CacheSize == 0 || c.Employees.CacheTTL > 0, "employees.cache_ttl must be positive with the cache enabled")
+
+	return problems
+}
+
+// Print writes the effective configuration as YAML with secrets redacted.
+func Print(w io.Writer, c *Config) error {
+	root := map[string]any{}
+	for _, s := range settings(c) {
+		value := s.value.String()
+		if s.secret && value != "" {
+			value = "[REDACTED]"
+		}
+
+		section, name, _ := strings.Cut(s.key, ".")
+		if _, ok := root[section]; !ok {
+			root[section] = map[string]any{}
+		}
+		root[section].(map[string]any)[name] = value
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// DSN returns the connection string for lib/pq. Secret files are read on
+// every call, so pass the method itself to db.NewPostgresDB rather than its
+// result.
+func (c *Config) DSN() (string, error) {
+	dsn, _, err := c.dsn()
+	return dsn, err
+}
+
+// RedactedDSN is DSN with the password masked, safe to log.
+func (c *Config) RedactedDSN() string {
+	_, redacted, err := c.dsn()
+	if err != nil {
+		return "invalid connection settings: " + err.Error()
+	}
+	return redacted
+}
+
+func (c *Config) dsn() (string, string, error) {
+	password := c.DBPassword
+	if c.DBPasswordFile != "" {
+		var err error
+		if password, err = readSecret(c.DBPasswordFile); err != nil {
+			return "", "", fmt.Errorf("db.password_file: %w", err)
+		}
+	}
+
+	rawURL := c.DBURL
+	if c.DBURLFile != "" {
+		var err error
+		if rawURL, err = readSecret(c.DBURLFile); err != nil {
+			return "", "", fmt.Errorf("db.url_file: %w", err)
+		}
+	}
+
+	if rawURL != "" {
+		u, err := url.Parse(rawURL)
+		if err != nil || (u.Scheme != "postgres" && u.Scheme != "postgresql") {
+			// Never echo the URL back, it usually carries the password.
+			return "", "", errors.New("db.url must be a postgres:// or postgresql:// URL")
+		}
+		// A password file fills in a URL that only names the user.
+		if u.User != nil && c.DBPasswordFile != "" {
+			if _, ok := u.User.Password(); !ok {
+				u.User = url.UserPassword(u.User.Username(), password)
+			}
+		}
+		return u.String(), u.Redacted(), nil
+	}
+
+	keywords := func(password string) string {
+		return fmt.Sprintf(
+			"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+			quoteDSN(c.DBHost),
+			quoteDSN(c.DBPort),
+			quoteDSN(c.DBUser),
+			quoteDSN(password),
+			quoteDSN(c.DBName),
+			quoteDSN(c.DBSSLMode),
+		)
+	}
+	return keywords(password), keywords("xxxxx"), nil
+}
+
+// quoteDSN quotes a keyword/value connection string value when needed, so
+// passwords containing spaces or quotes survive.
+func quoteDSN(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// readSecret reads a Docker or Kubernetes style secret file, dropping the
+// trailing newline editors and `echo` add.
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read secret file: %w", err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// readFile parses a YAML or TOML file, chosen by extension, into dotted keys
+// such as "db.host". Lists are joined with commas like their env variables.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	raw := map[string]any{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &raw)
+	case ".toml":
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("config file %s must end in .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := map[string]string{}
+	flatten("", raw, values)
+	return values, nil
+}
+
+func flatten(prefix string, raw map[string]any, out map[string]string) {
+	for key, value := range raw {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(key, v, out)
+		case []any:
+			items := make([]string, len(v))
+			for i := range v {
+				items[i] = fmt.Sprint(v[i])
+			}
+			out[key] = strings.Join(items, ",")
+		case time.Time:
+			out[key] = v.Format("2006-01-02")
+		default:
+			out[key] = fmt.Sprint(v)
+		}
+	}
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func getEnv(key, defaultValue string) string {
@@ -64,4 +616,4 @@ func getEnv(key, defaultValue string) string {
 	}
 
 	return defaultValue
-}
\ No newline at end of file
+}
@@ -0,0 +1,305 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// setting ties one configuration value to its config file key (also used as
+// the flag name), its environment variable and its default.
+type setting struct {
+	key    string
+	env    string
+	def    string
+	usage  string
+	secret bool
+	value  value
+}
+
+// value has the same shape as flag.Value: Set parses and stores, String
+// formats the current value.
+type value interface {
+	Set(string) error
+	String() string
+}
+
+func settings(c *Config) []setting {
+	return []setting{
+		{key: "db.host", env: "DB_HOST", def: "localhost", usage: "database host", value: (*stringValue)(&c.DBHost)},
+		{key: "db.port", env: "DB_PORT", def: "5433", usage: "database port", value: (*stringValue)(&c.DBPort)},
+		{key: "db.user", env: "DB_USER", def: "postgres", usage: "database user", value: (*stringValue)(&c.DBUser)},
+		{key: "db.password", env: "DB_PASSWORD", def: "postgres", usage: "database password", secret: true, value: (*stringValue)(&c.DBPassword)},
+		{key: "db.name", env: "DB_NAME", def: "employee_db", usage: "database name", value: (*stringValue)(&c.DBName)},
+		{key: "db.password_file", env: "DB_PASSWORD_FILE", def: "", usage: "file holding the database password, re-read on reconnect", value: (*stringValue)(&c.DBPasswordFile)},
+		{key: "db.url", env: "DATABASE_URL", def: "", usage: "postgres:// connection URL, replaces the other db.* connection settings", secret: true, value: (*stringValue)(&c.DBURL)},
+		{key: "db.url_file", env: "DATABASE_URL_FILE", def: "", usage: "file holding the connection URL, re-read on reconnect", value: (*stringValue)(&c.DBURLFile)},
+		{key: "db.sslmode", env: "DB_SSLMODE", def: "disable", usage: "database sslmode", value: (*stringValue)(&c.DBSSLMode)},
+		{key: "db.max_open_conns", env: "DB_MAX_OPEN_CONNS", def: "25", usage: "maximum open database connections", value: (*intValue)(&c.DB.MaxOpenConns)},
+		{key: "db.max_idle_conns", env: "DB_MAX_IDLE_CONNS", def: "5", usage: "maximum idle database connections", value: (*intValue)(&c.DB.MaxIdleConns)},
+		{key: "db.conn_max_lifetime", env: "DB_CONN_MAX_LIFETIME", def: "30m", usage: "maximum lifetime of a database connection, 0 keeps them forever", value: (*durationValue)(&c.DB.ConnMaxLifetime)},
+		{key: "db.conn_max_idle_time", env: "DB_CONN_MAX_IDLE_TIME", def: "5m", usage: "close connections idle for longer than this, 0 keeps them", value: (*durationValue)(&c.DB.ConnMaxIdleTime)},
+		{key: "db.connect_timeout", env: "DB_CONNECT_TIMEOUT", def: "30s", usage: "how long startup retries an unreachable database", value: (*durationValue)(&c.DB.ConnectTimeout)},
+		{key: "db.ping_interval", env: "DB_PING_INTERVAL", def: "15s", usage: "interval of the background database health check", value: (*durationValue)(&c.DB.PingInterval)},
+		{key: "db.query_timeout", env: "DB_QUERY_TIMEOUT", def: "5s", usage: "timeout of a single repository query", value: (*durationValue)(&c.DB.QueryTimeout)},
+
+		{key: "server.port", env: "SERVER_PORT", def: "8080", usage: "HTTP listen port", value: (*stringValue)(&c.ServerPort)},
+		{key: "server.read_timeout", env: "HTTP_READ_TIMEOUT", def: "10s", usage: "HTTP read timeout", value: (*durationValue)(&c.HTTP.ReadTimeout)},
+		{key: "server.write_timeout", env: "HTTP_WRITE_TIMEOUT", def: "10s", usage: "HTTP write timeout", value: (*durationValue)(&c.HTTP.WriteTimeout)},
+		{key: "server.idle_timeout", env: "HTTP_IDLE_TIMEOUT", def: "1m", usage: "HTTP keep-alive idle timeout", value: (*durationValue)(&c.HTTP.IdleTimeout)},
+		{key: "server.shutdown_timeout", env: "HTTP_SHUTDOWN_TIMEOUT", def: "10s", usage: "time to drain requests on shutdown", value: (*durationValue)(&c.HTTP.ShutdownTimeout)},
+		{key: "server.shutdown_delay", env: "HTTP_SHUTDOWN_DELAY", def: "0s", usage: "time /readyz fails before the listener closes on shutdown", value: (*durationValue)(&c.HTTP.ShutdownDelay)},
+
+		{key: "compression.encodings", env: "COMPRESSION_ENCODINGS", def: "zstd,br,gzip", usage: "comma separated response encodings in order of preference, empty disables compression", value: (*listValue)(&c.Compression.Encodings)},
+		{key: "compression.min_bytes", env: "COMPRESSION_MIN_BYTES", def: "1024", usage: "smallest response in bytes that is compressed", value: (*intValue)(&c.Compression.MinBytes)},
+
+		{key: "health.check_timeout", env: "HEALTH_CHECK_TIMEOUT", def: "2s", usage: "timeout of each readiness check", value: (*durationValue)(&c.Health.CheckTimeout)},
+		{key: "health.cache_ttl", env: "HEALTH_CACHE_TTL", def: "2s", usage: "how long readiness results are reused", value: (*durationValue)(&c.Health.CacheTTL)},
+		{key: "health.min_free_bytes", env: "HEALTH_MIN_FREE_BYTES", def: "104857600", usage: "free bytes the attachment directory needs to be ready", value: (*int64Value)(&c.Health.MinFreeBytes)},
+
+		{key: "tracing.exporter", env: "TRACING_EXPORTER", def: "none", usage: "trace exporter: none, stdout, file or otlp", value: (*stringValue)(&c.Tracing.Exporter)},
+		{key: "tracing.endpoint", env: "TRACING_OTLP_ENDPOINT", def: "", usage: "OTLP/HTTP endpoint URL, e.g. http://localhost:4318", value: (*stringValue)(&c.Tracing.Endpoint)},
+		{key: "tracing.file", env: "TRACING_FILE", def: "./data/traces.jsonl", usage: "file the file exporter appends spans to", value: (*stringValue)(&c.Tracing.File)},
+		{key: "tracing.sample_ratio", env: "TRACING_SAMPLE_RATIO", def: "1", usage: "fraction of new traces to sample", value: (*floatValue)(&c.Tracing.SampleRatio)},
+		{key: "tracing.service_name", env: "TRACING_SERVICE_NAME", def: "employee-api", usage: "service.name resource attribute", value: (*stringValue)(&c.Tracing.ServiceName)},
+
+		{key: "logging.level", env: "LOG_LEVEL", def: "info", usage: "log level: debug, info, warn or error", value: (*stringValue)(&c.Logging.Level)},
+		{key: "logging.format", env: "LOG_FORMAT", def: "json", usage: "log format: json or console", value: (*stringValue)(&c.Logging.Format)},
+
+		{key: "outbox.sinks", env: "OUTBOX_SINKS", def: "", usage: "comma separated event sinks: stdout, file, webhook", value: (*listValue)(&c.Outbox.Sinks)},
+		{key: "outbox.file", env: "OUTBOX_FILE", def: "./data/events.jsonl", usage: "file the file sink appends events to", value: (*stringValue)(&c.Outbox.File)},
+		{key: "outbox.webhook_url", env: "OUTBOX_WEBHOOK_URL", def: "", usage: "URL the webhook sink posts events to", value: (*stringValue)(&c.Outbox.WebhookURL)},
+		{key: "outbox.webhook_timeout", env: "OUTBOX_WEBHOOK_TIMEOUT", def: "10s", usage: "timeout of one webhook sink request", value: (*durationValue)(&c.Outbox.WebhookTimeout)},
+		{key: "outbox.poll_interval", env: "OUTBOX_POLL_INTERVAL", def: "1s", usage: "how often the dispatcher looks for new events", value: (*durationValue)(&c.Outbox.PollInterval)},
+		{key: "outbox.batch_size", env: "OUTBOX_BATCH_SIZE", def: "100", usage: "events read per dispatcher poll", value: (*intValue)(&c.Outbox.BatchSize)},
+		{key: "outbox.max_backoff", env: "OUTBOX_MAX_BACKOFF", def: "5m", usage: "longest delay between retries of a failing event", value: (*durationValue)(&c.Outbox.MaxBackoff)},
+		{key: "outbox.retention", env: "OUTBOX_RETENTION", def: "168h", usage: "how long published events are kept", value: (*durationValue)(&c.Outbox.Retention)},
+
+		{key: "webhooks.timeout", env: "WEBHOOKS_TIMEOUT", def: "10s", usage: "timeout of one webhook delivery request", value: (*durationValue)(&c.Webhooks.Timeout)},
+		{key: "webhooks.max_attempts", env: "WEBHOOKS_MAX_ATTEMPTS", def: "8", usage: "delivery attempts before a delivery is dead", value: (*intValue)(&c.Webhooks.MaxAttempts)},
+		{key: "webhooks.retry_base", env: "WEBHOOKS_RETRY_BASE", def: "30s", usage: "delay after the first failed delivery, doubled after each further one", value: (*durationValue)(&c.Webhooks.RetryBase)},
+		{key: "webhooks.retry_max", env: "WEBHOOKS_RETRY_MAX", def: "6h", usage: "longest delay between delivery attempts", value: (*durationValue)(&c.Webhooks.RetryMax)},
+		{key: "webhooks.poll_interval", env: "WEBHOOKS_POLL_INTERVAL", def: "2s", usage: "how often the deliverer looks for due deliveries", value: (*durationValue)(&c.Webhooks.PollInterval)},
+		{key: "webhooks.batch_size", env: "WEBHOOKS_BATCH_SIZE", def: "50", usage: "deliveries claimed per deliverer poll", value: (*intValue)(&c.Webhooks.BatchSize)},
+		{key: "webhooks.workers", env: "WEBHOOKS_WORKERS", def: "4", usage: "deliveries sent concurrently", value: (*intValue)(&c.Webhooks.Workers)},
+		{key: "webhooks.allow_private_networks", env: "WEBHOOKS_ALLOW_PRIVATE_NETWORKS", def: "false", usage: "allow webhook URLs on loopback, private and link-local addresses", value: (*boolValue)(&c.Webhooks.AllowPrivateNetworks)},
+
+		{key: "stream.heartbeat", env: "STREAM_HEARTBEAT", def: "15s", usage: "interval of heartbeat comments on idle event streams", value: (*durationValue)(&c.Stream.Heartbeat)},
+		{key: "stream.client_buffer", env: "STREAM_CLIENT_BUFFER", def: "256", usage: "changes a stream client may fall behind before it is disconnected", value: (*intValue)(&c.Stream.ClientBuffer)},
+		{key: "stream.max_clients", env: "STREAM_MAX_CLIENTS", def: "1000", usage: "concurrent event stream clients per instance", value: (*intValue)(&c.Stream.MaxClients)},
+		{key: "stream.poll_interval", env: "STREAM_POLL_INTERVAL", def: "30s", usage: "how often the change log is read without a notification", value: (*durationValue)(&c.Stream.PollInterval)},
+		{key: "stream.retention", env: "STREAM_RETENTION", def: "24h", usage: "how long changes are kept for resuming clients", value: (*durationValue)(&c.Stream.Retention)},
+
+		{key: "grpc.port", env: "GRPC_PORT", def: "9090", usage: "gRPC listen port", value: (*stringValue)(&c.GRPC.Port)},
+		{key: "grpc.reflection", env: "GRPC_REFLECTION", def: "true", usage: "serve gRPC server reflection", value: (*boolValue)(&c.GRPC.Reflection)},
+
+		{key: "graphql.max_depth", env: "GRAPHQL_MAX_DEPTH", def: "10", usage: "maximum field nesting of a GraphQL query", value: (*intValue)(&c.GraphQL.MaxDepth)},
+		{key: "graphql.max_complexity", env: "GRAPHQL_MAX_COMPLEXITY", def: "5000", usage: "maximum complexity of a GraphQL query", value: (*intValue)(&c.GraphQL.MaxComplexity)},
+		{key: "graphql.graphiql", env: "GRAPHQL_GRAPHIQL", def: "false", usage: "serve the GraphiQL IDE at /graphiql (development)", value: (*boolValue)(&c.GraphQL.GraphiQL)},
+
+		{key: "openapi.validate_requests", env: "OPENAPI_VALIDATE_REQUESTS", def: "true", usage: "reject requests that do not match the OpenAPI document", value: (*boolValue)(&c.OpenAPI.ValidateRequests)},
+		{key: "openapi.validate_responses", env: "OPENAPI_VALIDATE_RESPONSES", def: "false", usage: "check JSON responses against the OpenAPI document (test mode)", value: (*boolValue)(&c.OpenAPI.ValidateResponses)},
+
+		{key: "attendance.lock_date", env: "TIME_ENTRY_LOCK_DATE", def: "", usage: "time entries before this date (YYYY-MM-DD) are locked", value: (*dateValue)(&c.Attendance.LockDate)},
+		{key: "attendance.overtime_weekly_hours", env: "OVERTIME_WEEKLY_HOURS", def: "40", usage: "weekly hours before overtime starts", value: (*floatValue)(&c.Attendance.WeeklyHours)},
+
+		{key: "attachments.dir", env: "ATTACHMENT_DIR", def: "./data/attachments", usage: "attachment storage directory", value: (*stringValue)(&c.Attachments.Dir)},
+		{key: "attachments.max_bytes", env: "ATTACHMENT_MAX_BYTES", def: "10485760", usage: "maximum attachment size in bytes", value: (*int64Value)(&c.Attachments.MaxBytes)},
+		{key: "attachments.allowed_types", env: "ATTACHMENT_ALLOWED_TYPES", def: "application/pdf,image/jpeg,image/png", usage: "comma separated allowed attachment MIME types", value: (*listValue)(&c.Attachments.AllowedTypes)},
+
+		{key: "auth.api_tokens", env: "API_TOKENS", def: "", usage: "comma separated name:token pairs", secret: true, value: (*tokensValue)(&c.APITokens)},
+		{key: "auth.api_tokens_file", env: "API_TOKENS_FILE", def: "", usage: "file holding name:token pairs, replaces auth.api_tokens", value: (*stringValue)(&c.APITokensFile)},
+		{key: "auth.roles", env: "API_ROLES", def: "", usage: "comma separated name:role pairs", value: (*rolesValue)(&c.APIRoles)},
+		{key: "auth.employees", env: "API_EMPLOYEES", def: "", usage: "comma separated name:employee_id pairs, who callers act as", value: (*employeesValue)(&c.APIEmployees)},
+
+		{key: "employees.restricted_fields", env: "EMPLOYEE_RESTRICTED_FIELDS", def: "", usage: "comma separated employee fields only privileged roles can read", value: (*listValue)(&c.Employees.RestrictedFields)},
+		{key: "employees.privileged_roles", env: "EMPLOYEE_PRIVILEGED_ROLES", def: "admin,hr", usage: "comma separated roles that can read restricted employee fields", value: (*listValue)(&c.Employees.PrivilegedRoles)},
+		{key: "employees.cache_max_age", env: "EMPLOYEE_CACHE_MAX_AGE", def: "0s", usage: "max-age of employee reads, 0 makes clients revalidate", value: (*durationValue)(&c.Employees.CacheMaxAge)},
+		{key: "employees.cache_size", env: "EMPLOYEE_CACHE_SIZE", def: "1000", usage: "employees read by ID kept in the in-process cache, 0 disables it", value: (*intValue)(&c.Employees.CacheSize)},
+		{key: "employees.cache_ttl", env: "EMPLOYEE_CACHE_TTL", def: "30s", usage: "how long an employee stays in the in-process cache", value: (*durationValue)(&c.Employees.CacheTTL)},
+	}
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error { *v = stringValue(strings.TrimSpace(s)); return nil }
+func (v *stringValue) String() string     { return string(*v) }
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v = intValue(n)
+	return nil
+}
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+	b, err := strconv.ParseBool(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%q is not true or false", s)
+	}
+	*v = boolValue(b)
+	return nil
+}
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+type int64Value int64
+
+func (v *int64Value) Set(s string) error {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v = int64Value(n)
+	return nil
+}
+func (v *int64Value) String() string { return strconv.FormatInt(int64(*v), 10) }
+
+type floatValue float64
+
+func (v *floatValue) Set(s string) error {
+	n, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return fmt.Errorf("%q is not a number", s)
+	}
+	*v = floatValue(n)
+	return nil
+}
+func (v *floatValue) String() string { return strconv.FormatFloat(float64(*v), 'f', -1, 64) }
+
+type durationValue time.Duration
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(strings.TrimSpace(s))
+	if err != nil {
+		return fmt.Errorf("%q is not a duration such as 5s or 1m30s", s)
+	}
+	*v = durationValue(d)
+	return nil
+}
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+// dateValue accepts an empty string as the zero time.
+type dateValue time.Time
+
+func (v *dateValue) Set(s string) error {
+	if s = strings.TrimSpace(s); s == "" {
+		*v = dateValue(time.Time{})
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return fmt.Errorf("%q is not a date formatted as YYYY-MM-DD", s)
+	}
+	*v = dateValue(t)
+	return nil
+}
+func (v *dateValue) String() string {
+	if time.Time(*v).IsZero() {
+		return ""
+	}
+	return time.Time(*v).Format("2006-01-02")
+}
+
+type listValue []string
+
+func (v *listValue) Set(s string) error { *v = splitList(s); return nil }
+func (v *listValue) String() string     { return strings.Join(*v, ",") }
+
+// tokensValue parses name:token pairs into a token to name map.
+type tokensValue map[string]string
+
+func (v *tokensValue) Set(s string) error {
+	tokens := map[string]string{}
+	for _, pair := range splitList(s) {
+		name, token, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || token == "" {
+			return fmt.Errorf("invalid entry %q, expected name:token", pair)
+		}
+		tokens[token] = name
+	}
+	*v = tokens
+	return nil
+}
+func (v *tokensValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for token, name := range *v {
+		pairs = append(pairs, name+":"+token)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// rolesValue parses name:role pairs into a name to role map.
+type rolesValue map[string]string
+
+func (v *rolesValue) Set(s string) error {
+	roles := map[string]string{}
+	for _, pair := range splitList(s) {
+		name, role, ok := strings.Cut(pair, ":")
+		if !ok || name == "" || role == "" {
+			return fmt.Errorf("invalid entry %q, expected name:role", pair)
+		}
+		roles[name] = role
+	}
+	*v = roles
+	return nil
+}
+func (v *rolesValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for name, role := range *v {
+		pairs = append(pairs, name+":"+role)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// employeesValue parses name:employee_id pairs into a name to employee ID
+// map.
+type employeesValue map[string]int64
+
+func (v *employeesValue) Set(s string) error {
+	employees := map[string]int64{}
+	for _, pair := range splitList(s) {
+		name, id, ok := strings.Cut(pair, ":")
+		n, err := strconv.ParseInt(id, 10, 64)
+		if !ok || name == "" || err != nil || n <= 0 {
+			return fmt.Errorf("invalid entry %q, expected name:employee_id", pair)
+		}
+		employees[name] = n
+	}
+	*v = employees
+	return nil
+}
+
+func (v *employeesValue) String() string {
+	pairs := make([]string, 0, len(*v))
+	for name, id := range *v {
+		pairs = append(pairs, name+":"+strconv.FormatInt(id, 10))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func validPort(port string) bool {
+	n, err := strconv.Atoi(port)
+	return err == nil && n > 0 && n <= 65535
+}
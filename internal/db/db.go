@@ -1,27 +1,84 @@
 package db
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"log"
+	"time"
+
 	cfg "github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
 )
 
-func NewPostgresDB(connStr string, dbConf cfg.DbConfig) (*sql.DB, error) {
-	db, err := sql.Open("postgres", connStr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open datbase: %w", err);
-	}
+const (
+	initialBackoff = 250 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+	pingTimeout    = 5 * time.Second
+)
 
-	if err := db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %w", err);
-	}
+// NewPostgresDB opens a pool whose connections each ask dsn for a fresh
+// connection string, so credentials rotated on disk apply to the next
+// connection the pool dials. Postgres that is still starting up is retried
+// with exponential backoff until dbConf.ConnectTimeout has passed.
+func NewPostgresDB(dsn func() (string, error), dbConf cfg.DbConfig, logger *zap.SugaredLogger) (*sql.DB, error) {
+	db := sql.OpenDB(connector{dsn: dsn})
 
 	db.SetMaxOpenConns(dbConf.MaxOpenConns)
 	db.SetMaxIdleConns(dbConf.MaxIdleConns)
+	db.SetConnMaxLifetime(dbConf.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(dbConf.ConnMaxIdleTime)
+
+	if err := waitForDB(db, dbConf.ConnectTimeout, logger); err != nil {
+		db.Close()
+		return nil, err
+	}
 
-	log.Println("ping connedct");
 	return db, nil
-}
\ No newline at end of file
+}
+
+func waitForDB(db *sql.DB, timeout time.Duration, logger *zap.SugaredLogger) error {
+	deadline := time.Now().Add(timeout)
+	backoff := initialBackoff
+
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+		err := db.PingContext(ctx)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		wait := min(backoff, time.Until(deadline))
+		if wait <= 0 {
+			return fmt.Errorf("failed to ping database after %d attempts: %w", attempt, err)
+		}
+
+		logger.Warnw("database not ready, retrying", "attempt", attempt, "retry_in", wait.String(), "error", err)
+		time.Sleep(wait)
+		backoff = min(backoff*2, maxBackoff)
+	}
+}
+
+type connector struct {
+	dsn func() (string, error)
+}
+
+func (c connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dsn, err := c.dsn()
+	if err != nil {
+		return nil, err
+	}
+
+	pc, err := pq.NewConnector(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open datbase: %w", err)
+	}
+	return pc.Connect(ctx)
+}
+
+func (connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
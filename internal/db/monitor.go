@@ -0,0 +1,118 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"time"
+)
+
+// Monitor pings the database in the background and remembers whether the
+// last ping succeeded, so readiness can be answered without touching the
+// pool on every request.
+type Monitor struct {
+	db       *sql.DB
+	interval time.Duration
+
+	mu      sync.RWMutex
+	ready   bool
+	lastErr error
+	checked time.Time
+}
+
+// Status is a snapshot of the database health and pool statistics.
+type Status struct {
+	Ready     bool      `json:"ready"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+	Pool      PoolStats `json:"pool"`
+}
+
+// PoolStats mirrors sql.DBStats with JSON names and durations in seconds.
+type PoolStats struct {
+	MaxOpenConnections int     `json:"max_open_connections"`
+	OpenConnections    int     `json:"open_connections"`
+	InUse              int     `json:"in_use"`
+	Idle               int     `json:"idle"`
+	WaitCount          int64   `json:"wait_count"`
+	WaitDurationSecs   float64 `json:"wait_duration_seconds"`
+	MaxIdleClosed      int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64   `json:"max_lifetime_closed"`
+}
+
+// NewMonitor starts out ready, since NewPostgresDB only returns a pool it
+// could ping.
+func NewMonitor(db *sql.DB, interval time.Duration) *Monitor {
+	return &Monitor{db: db, interval: interval, ready: true, checked: time.Now()}
+}
+
+// Run pings every interval until ctx is cancelled.
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.check(ctx)
+		}
+	}
+}
+
+func (m *Monitor) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+	err := m.db.PingContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ready = err == nil
+	m.lastErr = err
+	m.checked = time.Now()
+}
+
+// Ready reports whether the last ping succeeded.
+func (m *Monitor) Ready() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.ready
+}
+
+// Check returns the error of the last ping, making the monitor the
+// database check of readiness.
+func (m *Monitor) Check(context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.lastErr
+}
+
+// Stats returns the pool statistics of the monitored database.
+func (m *Monitor) Stats() sql.DBStats {
+	return m.db.Stats()
+}
+
+func (m *Monitor) Status() Status {
+	m.mu.RLock()
+	status := Status{Ready: m.ready, CheckedAt: m.checked}
+	if m.lastErr != nil {
+		status.Error = m.lastErr.Error()
+	}
+	m.mu.RUnlock()
+
+	stats := m.db.Stats()
+	status.Pool = PoolStats{
+		MaxOpenConnections: stats.MaxOpenConnections,
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitDurationSecs:   stats.WaitDuration.Seconds(),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+	return status
+}
@@ -0,0 +1,53 @@
+package attachmentEntity
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+const (
+	CategoryContract = "contract"
+	CategoryIDScan   = "id_scan"
+	CategoryPhoto    = "photo"
+	CategoryOther    = "other"
+)
+
+type Attachment struct {
+	ID          int64     `json:"id" example:"1"`
+	EmployeeID  int64     `json:"employee_id" example:"1"`
+	Filename    string    `json:"filename" example:"contract.pdf"`
+	Category    string    `json:"category" example:"contract"`
+	ContentType string    `json:"content_type" example:"application/pdf"`
+	SizeBytes   int64     `json:"size_bytes" example:"52344"`
+	SHA256      string    `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  string    `json:"uploaded_by" example:"admin"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Upload is an attachment as received from a client, before it is stored.
+type Upload struct {
+	EmployeeID int64
+	Filename   string
+	Category   string
+	// ExpectedSHA256 is the hex digest the client claims for the file, the
+	// upload is rejected when the received bytes do not match it.
+	ExpectedSHA256 string
+	UploadedBy     string
+	Body           io.Reader
+}
+
+// EmployeeKeyPrefix groups the blobs of one employee so they can be removed
+// together when the employee is deleted.
+func EmployeeKeyPrefix(employeeID int64) string {
+	return fmt.Sprintf("employees/%d/", employeeID)
+}
+
+func ValidCategory(category string) bool {
+	switch category {
+	case CategoryContract, CategoryIDScan, CategoryPhoto, CategoryOther:
+		return true
+	}
+	return false
+}
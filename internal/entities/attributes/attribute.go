@@ -0,0 +1,154 @@
+package attributeEntity
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeBoolean = "boolean"
+	TypeEnum    = "enum"
+)
+
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+type Definition struct {
+	ID         int64     `json:"id" example:"1"`
+	Name       string    `json:"name" example:"cost_center"`
+	Type       string    `json:"type" example:"enum"`
+	Required   bool      `json:"required" example:"false"`
+	EnumValues []string  `json:"enum_values,omitempty" example:"CC-100,CC-200"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ValidationError collects every problem found in a set of attributes so the
+// client can fix them in one go.
+type ValidationError struct {
+	Problems []string
+}
+
+func (v *ValidationError) Error() string {
+	return "invalid attributes: " + strings.Join(v.Problems, "; ")
+}
+
+// CheckDefinition validates a definition before it is stored.
+func CheckDefinition(def *Definition) error {
+	if !namePattern.MatchString(def.Name) {
+		return fmt.Errorf("name must be lowercase letters, digits and underscores, starting with a letter")
+	}
+
+	switch def.Type {
+	case TypeString, TypeNumber, TypeBoolean:
+		if len(def.EnumValues) > 0 {
+			return fmt.Errorf("enum_values are only allowed for enum attributes")
+		}
+	case TypeEnum:
+		if len(def.EnumValues) == 0 {
+			return fmt.Errorf("enum attributes need at least one enum value")
+		}
+	default:
+		return fmt.Errorf("type must be one of string, number, boolean or enum")
+	}
+
+	return nil
+}
+
+// Validate checks attributes against the definitions: unknown names are
+// rejected, required attributes must be present and every value must match
+// its definition's type.
+func Validate(attrs map[string]any, defs []Definition) error {
+	byName := make(map[string]*Definition, len(defs))
+	for i := range defs {
+		byName[defs[i].Name] = &defs[i]
+	}
+
+	var problems []string
+	for name, value := range attrs {
+		def, ok := byName[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a defined attribute", name))
+			continue
+		}
+		if value == nil {
+			if def.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", name))
+			}
+			continue
+		}
+		if err := checkValue(def, value); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	for i := range defs {
+		if _, ok := attrs[defs[i].Name]; defs[i].Required && !ok {
+			problems = append(problems, fmt.Sprintf("%s is required", defs[i].Name))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// ParseFilter converts a raw query string value to the JSON type of the
+// attribute so it can be matched with JSONB containment.
+func ParseFilter(def *Definition, raw string) (any, error) {
+	var value any = raw
+	switch def.Type {
+	case TypeNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", def.Name)
+		}
+		value = n
+	case TypeBoolean:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false", def.Name)
+		}
+		value = b
+	}
+
+	if err := checkValue(def, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func checkValue(def *Definition, value any) error {
+	switch def.Type {
+	case TypeString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s must be a string", def.Name)
+		}
+	case TypeNumber:
+		if _, ok := value.(float64); !ok {
+			return fmt.Errorf("%s must be a number", def.Name)
+		}
+	case TypeBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s must be a boolean", def.Name)
+		}
+	case TypeEnum:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s must be one of %s", def.Name, strings.Join(def.EnumValues, ", "))
+		}
+		for _, allowed := range def.EnumValues {
+			if s == allowed {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", def.Name, strings.Join(def.EnumValues, ", "))
+	}
+	return nil
+}
@@ -8,5 +8,28 @@ type Employee struct {
 	Email      string  `json:"email" example:"john.doe@example.com"`
 	Position   string  `json:"position" example:"Software Engineer"`
 	Salary     float64 `json:"salary" example:"100000"`
+	ManagerID  *int64  `json:"manager_id,omitempty" example:"2"`
+	Attributes map[string]any `json:"attributes,omitempty"`
 	CreatedAt time.Time `json:"created_at"`
-}
\ No newline at end of file
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ListFilter narrows down GetAll. Attributes are matched exactly against the
+// employee's custom attributes, IDs restricts the result to those employees.
+// Results are ordered by ID; AfterID and Limit page through them, a Limit of
+// 0 returns everything. Fields, as returned by ParseFields, limits the
+// columns read; the other fields are left at their zero value. Nil reads
+// every field.
+type ListFilter struct {
+	Attributes map[string]any
+	IDs []int64
+	AfterID int64
+	Limit int
+	Fields []string
+}
+
+// ValidationError is returned for employee input that is rejected before it
+// reaches the database.
+type ValidationError string
+
+func (e ValidationError) Error() string { return string(e) }
@@ -0,0 +1,96 @@
+package employeeEntity
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Fields are the fields of an employee by their JSON name, in the order
+// they are encoded.
+var Fields = []string{"id", "name", "email", "position", "salary", "manager_id", "attributes", "created_at", "updated_at"}
+
+// ParseFields reads a comma separated list of field names, like the value
+// of ?fields=. The result is in the order of Fields, without duplicates,
+// and always includes the id.
+func ParseFields(list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+		if !slices.Contains(Fields, name) {
+			return nil, ValidationError(fmt.Sprintf("unknown field %q, allowed are %s", name, strings.Join(Fields, ", ")))
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return nil, ValidationError("fields must name at least one field")
+	}
+
+	fields := []string{"id"}
+	for _, name := range Fields[1:] {
+		if slices.Contains(names, name) {
+			fields = append(fields, name)
+		}
+	}
+	return fields, nil
+}
+
+// RestrictedFieldError is returned for a request naming a field the caller's
+// role may not read.
+type RestrictedFieldError string
+
+func (e RestrictedFieldError) Error() string {
+	return fmt.Sprintf("field %q is restricted", string(e))
+}
+
+// Redact returns emp with the fields missing from visible cleared. A nil
+// visible keeps every field; the id is always kept.
+func Redact(emp Employee, visible []string) Employee {
+	if visible == nil {
+		return emp
+	}
+	out := Employee{ID: emp.ID}
+	for _, field := range visible {
+		switch field {
+		case "name":
+			out.Name = emp.Name
+		case "email":
+			out.Email = emp.Email
+		case "position":
+			out.Position = emp.Position
+		case "salary":
+			out.Salary = emp.Salary
+		case "manager_id":
+			out.ManagerID = emp.ManagerID
+		case "attributes":
+			out.Attributes = emp.Attributes
+		case "created_at":
+			out.CreatedAt = emp.CreatedAt
+		case "updated_at":
+			out.UpdatedAt = emp.UpdatedAt
+		}
+	}
+	return out
+}
+
+// RedactJSON removes the fields missing from visible from an employee
+// encoded as a JSON object, like the data of a change event. A nil visible
+// keeps every field.
+func RedactJSON(data json.RawMessage, visible []string) (json.RawMessage, error) {
+	if visible == nil {
+		return data, nil
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	for name := range obj {
+		if !slices.Contains(visible, name) {
+			delete(obj, name)
+		}
+	}
+	return json.Marshal(obj)
+}
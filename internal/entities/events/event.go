@@ -0,0 +1,27 @@
+package eventEntity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	EmployeeCreated = "employee.created"
+	EmployeeUpdated = "employee.updated"
+	EmployeeDeleted = "employee.deleted"
+)
+
+// Types lists every event type, in the order they are documented.
+var Types = []string{EmployeeCreated, EmployeeUpdated, EmployeeDeleted}
+
+// Event is a change to an employee, recorded in the outbox in the same
+// transaction as the change itself. Data is the employee as it was after
+// the change, or right before it for deletions.
+type Event struct {
+	ID         int64           `json:"id" example:"42"`
+	Type       string          `json:"type" example:"employee.updated"`
+	EmployeeID int64           `json:"employee_id" example:"1"`
+	Data       json.RawMessage `json:"data"`
+	CreatedAt  time.Time       `json:"created_at"`
+	Attempts   int             `json:"-"`
+}
@@ -0,0 +1,84 @@
+package leaveEntity
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	AccrualAnnual  = "annual"
+	AccrualMonthly = "monthly"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+)
+
+type LeaveType struct {
+	ID            int64     `json:"id" example:"1"`
+	Name          string    `json:"name" example:"Annual Leave"`
+	AnnualDays    float64   `json:"annual_days" example:"12"`
+	AccrualMethod string    `json:"accrual_method" example:"monthly"`
+	MaxCarryOver  float64   `json:"max_carry_over" example:"5"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type LeaveBalance struct {
+	EmployeeID      int64   `json:"employee_id" example:"1"`
+	LeaveTypeID     int64   `json:"leave_type_id" example:"1"`
+	LeaveTypeName   string  `json:"leave_type_name" example:"Annual Leave"`
+	Year            int     `json:"year" example:"2026"`
+	AccruedDays     float64 `json:"accrued_days" example:"10"`
+	CarriedOverDays float64 `json:"carried_over_days" example:"2"`
+	UsedDays        float64 `json:"used_days" example:"3"`
+	PendingDays     float64 `json:"pending_days" example:"1"`
+	AvailableDays   float64 `json:"available_days" example:"8"`
+}
+
+type LeaveRequest struct {
+	ID          int64      `json:"id" example:"1"`
+	EmployeeID  int64      `json:"employee_id" example:"1"`
+	LeaveTypeID int64      `json:"leave_type_id" example:"1"`
+	StartDate   time.Time  `json:"start_date" example:"2026-03-02T00:00:00Z"`
+	EndDate     time.Time  `json:"end_date" example:"2026-03-06T00:00:00Z"`
+	Days        float64    `json:"days" example:"5"`
+	Reason      string     `json:"reason" example:"Family holiday"`
+	Status      string     `json:"status" example:"pending"`
+	DecidedBy   *int64     `json:"decided_by,omitempty" example:"2"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// AccruedDays returns how many days of this leave type have been earned in
+// the calendar year of asOf. Annual types grant the full allowance on the
+// first of January, monthly types earn a twelfth at the start of each month.
+func (t *LeaveType) AccruedDays(asOf time.Time) float64 {
+	switch t.AccrualMethod {
+	case AccrualMonthly:
+		accrued := t.AnnualDays * float64(asOf.Month()) / 12
+		return math.Round(accrued*100) / 100
+	default:
+		return t.AnnualDays
+	}
+}
+
+// CarryOver caps what is left of last year's balance at the type's limit.
+func (t *LeaveType) CarryOver(remaining float64) float64 {
+	if remaining <= 0 {
+		return 0
+	}
+	return math.Min(remaining, t.MaxCarryOver)
+}
+
+// WorkingDays counts the weekdays between start and end, both inclusive.
+func WorkingDays(start, end time.Time) float64 {
+	var days float64
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		if d.Weekday() != time.Saturday && d.Weekday() != time.Sunday {
+			days++
+		}
+	}
+	return days
+}
@@ -0,0 +1,112 @@
+package timeEntryEntity
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+type TimeEntry struct {
+	ID         int64      `json:"id" example:"1"`
+	EmployeeID int64      `json:"employee_id" example:"1"`
+	ClockIn    time.Time  `json:"clock_in" example:"2026-03-02T09:00:00Z"`
+	ClockOut   *time.Time `json:"clock_out,omitempty" example:"2026-03-02T17:30:00Z"`
+	Note       string     `json:"note" example:"On site at customer"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Hours returns the length of a closed entry, open entries count as zero.
+func (t *TimeEntry) Hours() float64 {
+	if t.ClockOut == nil {
+		return 0
+	}
+	return t.ClockOut.Sub(t.ClockIn).Hours()
+}
+
+type TimesheetDay struct {
+	Date  string  `json:"date" example:"2026-03-02"`
+	Hours float64 `json:"hours" example:"8.5"`
+}
+
+type TimesheetWeek struct {
+	WeekStart     string  `json:"week_start" example:"2026-03-02"`
+	Hours         float64 `json:"hours" example:"42.5"`
+	RegularHours  float64 `json:"regular_hours" example:"40"`
+	OvertimeHours float64 `json:"overtime_hours" example:"2.5"`
+}
+
+type Timesheet struct {
+	EmployeeID    int64           `json:"employee_id" example:"1"`
+	From          string          `json:"from" example:"2026-03-02"`
+	To            string          `json:"to" example:"2026-03-08"`
+	TotalHours    float64         `json:"total_hours" example:"42.5"`
+	OvertimeHours float64         `json:"overtime_hours" example:"2.5"`
+	Days          []TimesheetDay  `json:"days"`
+	Weeks         []TimesheetWeek `json:"weeks"`
+	Entries       []TimeEntry     `json:"entries"`
+}
+
+const dateLayout = "2006-01-02"
+
+// BuildTimesheet totals closed entries per day and per Monday-based week.
+// An entry is attributed to the day it was clocked in on, and every hour of
+// a week beyond weeklyHours counts as overtime.
+func BuildTimesheet(employeeID int64, from, to time.Time, entries []TimeEntry, weeklyHours float64) *Timesheet {
+	sheet := &Timesheet{
+		EmployeeID: employeeID,
+		From:       from.Format(dateLayout),
+		To:         to.Format(dateLayout),
+		Days:       []TimesheetDay{},
+		Weeks:      []TimesheetWeek{},
+		Entries:    entries,
+	}
+
+	days := map[string]float64{}
+	weeks := map[string]float64{}
+	for i := range entries {
+		hours := entries[i].Hours()
+		if hours == 0 {
+			continue
+		}
+		clockIn := entries[i].ClockIn.UTC()
+		days[clockIn.Format(dateLayout)] += hours
+		weeks[weekStart(clockIn).Format(dateLayout)] += hours
+	}
+
+	for date, hours := range days {
+		sheet.Days = append(sheet.Days, TimesheetDay{Date: date, Hours: round(hours)})
+	}
+	sort.Slice(sheet.Days, func(i, j int) bool { return sheet.Days[i].Date < sheet.Days[j].Date })
+
+	for start, hours := range weeks {
+		week := TimesheetWeek{WeekStart: start, Hours: round(hours), RegularHours: round(hours)}
+		if hours > weeklyHours {
+			week.RegularHours = round(weeklyHours)
+			week.OvertimeHours = round(hours - weeklyHours)
+		}
+		sheet.Weeks = append(sheet.Weeks, week)
+		sheet.TotalHours += week.Hours
+		sheet.OvertimeHours += week.OvertimeHours
+	}
+	sort.Slice(sheet.Weeks, func(i, j int) bool { return sheet.Weeks[i].WeekStart < sheet.Weeks[j].WeekStart })
+
+	sheet.TotalHours = round(sheet.TotalHours)
+	sheet.OvertimeHours = round(sheet.OvertimeHours)
+	return sheet
+}
+
+// AlignToWeeks widens [from, to] to whole ISO weeks, Monday to Sunday, so
+// the overtime of the first and last week is computed on all their hours.
+func AlignToWeeks(from, to time.Time) (time.Time, time.Time) {
+	return weekStart(from), weekStart(to).AddDate(0, 0, 6)
+}
+
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC)
+}
+
+func round(hours float64) float64 {
+	return math.Round(hours*100) / 100
+}
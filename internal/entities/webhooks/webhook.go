@@ -0,0 +1,152 @@
+package webhookEntity
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+)
+
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	// StatusDead marks a delivery that ran out of attempts. It stays in the
+	// log and can be redelivered by hand.
+	StatusDead = "dead"
+
+	// EventTest is only sent by the send test event endpoint.
+	EventTest = "webhook.test"
+)
+
+// Subscription receives the employee events listed in Events, or all of
+// them when Events is empty. Secret is only returned when the subscription
+// is created.
+type Subscription struct {
+	ID          int64     `json:"id" example:"1"`
+	URL         string    `json:"url" example:"https://hooks.example.com/employees"`
+	Description string    `json:"description" example:"Payroll sync"`
+	Events      []string  `json:"events" example:"employee.created,employee.deleted"`
+	Secret      string    `json:"secret,omitempty" example:"5f2b..."`
+	Active      bool      `json:"active" example:"true"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Wants reports whether the subscription receives events of eventType.
+func (s *Subscription) Wants(eventType string) bool {
+	return len(s.Events) == 0 || slices.Contains(s.Events, eventType)
+}
+
+// Delivery is one event sent, or still to be sent, to one subscription.
+type Delivery struct {
+	ID             int64           `json:"id" example:"10"`
+	SubscriptionID int64           `json:"subscription_id" example:"1"`
+	EventID        *int64          `json:"event_id" example:"42"`
+	EventType      string          `json:"event_type" example:"employee.created"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status" example:"pending"`
+	Attempts       int             `json:"attempts" example:"1"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at"`
+	LastStatusCode *int            `json:"last_status_code" example:"503"`
+	LastError      *string         `json:"last_error" example:"webhook answered 503 Service Unavailable"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+// Job is a due delivery together with where and how to send it.
+type Job struct {
+	Delivery
+	URL    string
+	Secret string
+}
+
+// Attempt is the outcome of sending a delivery once. StatusCode is 0 when
+// no response arrived.
+type Attempt struct {
+	DeliveryID int64
+	StatusCode int
+	Err        error
+	// Status is what the delivery moves to; a pending delivery is retried
+	// after RetryIn.
+	Status  string
+	RetryIn time.Duration
+}
+
+// ValidationError lists every problem with a subscription.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid webhook subscription: %v", e.Problems)
+}
+
+// Check validates the URL and event filter of s.
+func Check(s *Subscription) error {
+	invalid := &ValidationError{}
+
+	u, err := url.Parse(s.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		invalid.Problems = append(invalid.Problems, "url must be an absolute http or https URL")
+	}
+	for _, evt := range s.Events {
+		if !slices.Contains(eventEntity.Types, evt) {
+			invalid.Problems = append(invalid.Problems, fmt.Sprintf("unknown event %q, expected one of %v", evt, eventEntity.Types))
+		}
+	}
+	if len(s.Description) > 255 {
+		invalid.Problems = append(invalid.Problems, "description is longer than 255 characters")
+	}
+
+	if len(invalid.Problems) > 0 {
+		return invalid
+	}
+	return nil
+}
+
+// Blocked reports whether webhooks must not be sent to addr: loopback,
+// private (RFC 1918, fc00::/7), link-local, which holds cloud metadata
+// endpoints like 169.254.169.254, multicast and unspecified addresses.
+func Blocked(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() ||
+		addr.IsLinkLocalMulticast() || addr.IsMulticast() || addr.IsUnspecified()
+}
+
+// CheckHost rejects a URL whose host is localhost or a Blocked IP address.
+// Names are not resolved here; what they resolve to is checked when a
+// delivery connects.
+func CheckHost(s *Subscription) error {
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return nil
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	addr, err := netip.ParseAddr(host)
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") || (err == nil && Blocked(addr)) {
+		return &ValidationError{Problems: []string{"url must not point to a loopback, private or link-local address"}}
+	}
+	return nil
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp:
+// the hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the secret.
+// Including the timestamp lets receivers reject replayed requests.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,228 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"go.uber.org/zap"
+)
+
+// SubscriptionSink queues every event for the webhook subscriptions that
+// want it. The Deliverer sends the queued deliveries, so one slow
+// subscriber holds up neither the outbox nor the other subscribers.
+type SubscriptionSink struct {
+	store repository.WebhookRepository
+}
+
+func NewSubscriptionSink(store repository.WebhookRepository) *SubscriptionSink {
+	return &SubscriptionSink{store: store}
+}
+
+func (s *SubscriptionSink) Name() string { return "subscriptions" }
+
+func (s *SubscriptionSink) Publish(ctx context.Context, evt eventEntity.Event) error {
+	_, err := s.store.FanOut(ctx, evt)
+	return err
+}
+
+// Deliverer sends webhook deliveries. Several replicas can run one each:
+// deliveries are claimed with a lease, so each is sent by one of them.
+type Deliverer struct {
+	store  repository.WebhookRepository
+	client *http.Client
+	cfg    config.WebhookConfig
+	logger *zap.SugaredLogger
+}
+
+// NewDeliverer refuses to connect to addresses webhookEntity.Blocked
+// reports unless cfg.AllowPrivateNetworks is set. The check runs on every
+// address a subscription URL resolves to when it is dialed, so a name
+// pointed at an internal address later is caught too; proxies from the
+// environment are not used then, as they would be dialed instead.
+func NewDeliverer(store repository.WebhookRepository, cfg config.WebhookConfig, logger *zap.SugaredLogger) *Deliverer {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = refuseBlocked
+		transport.Proxy = nil
+	}
+	transport.DialContext = dialer.DialContext
+
+	return &Deliverer{
+		store: store,
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   cfg.Timeout,
+			// A redirect is answered like any other non 2xx status, the
+			// subscription URL has to be updated instead.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run sends due deliveries until ctx is cancelled.
+func (d *Deliverer) Run(ctx context.Context) {
+	d.logger.Info("webhook deliverer started")
+
+	for ctx.Err() == nil {
+		sent, err := d.deliverBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Errorw("failed to claim webhook deliveries", "error", err)
+		}
+
+		// A full batch means more is probably waiting.
+		if sent < d.cfg.BatchSize {
+			sleep(ctx, d.cfg.PollInterval)
+		}
+	}
+}
+
+// lease is how long a claimed batch is kept from other deliverers: long
+// enough to send all of it with every request timing out.
+func (d *Deliverer) lease() time.Duration {
+	rounds := (d.cfg.BatchSize + d.cfg.Workers - 1) / d.cfg.Workers
+	return time.Duration(rounds)*d.cfg.Timeout + time.Minute
+}
+
+func (d *Deliverer) deliverBatch(ctx context.Context) (int, error) {
+	jobs, err := d.store.ClaimDue(ctx, d.cfg.BatchSize, d.lease())
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	workers := make(chan struct{}, d.cfg.Workers)
+	for _, job := range jobs {
+		workers <- struct{}{}
+		wg.Go(func() {
+			defer func() { <-workers }()
+			if err := d.Deliver(ctx, job); err != nil && ctx.Err() == nil {
+				d.logger.Errorw("failed to record webhook delivery", "delivery_id", job.ID, "error", err)
+			}
+		})
+	}
+	wg.Wait()
+
+	return len(jobs), nil
+}
+
+// Deliver sends job once and records the outcome. A failed delivery is
+// retried with exponential backoff until it runs out of attempts and is
+// dead; test events are never retried. Nothing is recorded when ctx ends
+// mid-send, the delivery is sent again once its lease runs out.
+func (d *Deliverer) Deliver(ctx context.Context, job webhookEntity.Job) error {
+	statusCode, err := d.send(ctx, job)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	attempt := webhookEntity.Attempt{
+		DeliveryID: job.ID,
+		StatusCode: statusCode,
+		Err:        err,
+		Status:     webhookEntity.StatusSucceeded,
+	}
+	switch {
+	case err == nil:
+	case job.Attempts+1 >= d.cfg.MaxAttempts, job.EventType == webhookEntity.EventTest:
+		attempt.Status = webhookEntity.StatusDead
+		d.logger.Warnw("webhook delivery is dead",
+			"delivery_id", job.ID,
+			"subscription_id", job.SubscriptionID,
+			"attempts", job.Attempts+1,
+			"error", err,
+		)
+	default:
+		attempt.Status = webhookEntity.StatusPending
+		attempt.RetryIn = d.backoff(job.Attempts)
+		d.logger.Infow("webhook delivery failed",
+			"delivery_id", job.ID,
+			"subscription_id", job.SubscriptionID,
+			"attempt", job.Attempts+1,
+			"retry_in", attempt.RetryIn.String(),
+			"error", err,
+		)
+	}
+
+	return d.store.RecordAttempt(ctx, attempt)
+}
+
+// refuseBlocked is the dialer Control refusing blocked addresses.
+func refuseBlocked(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if webhookEntity.Blocked(addr) {
+		return fmt.Errorf("refusing to send webhook to non-public address %s", addr)
+	}
+	return nil
+}
+
+// send POSTs the payload signed with the subscription secret. It returns
+// the response status, 0 when there was none.
+func (d *Deliverer) send(ctx context.Context, job webhookEntity.Job) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Payload))
+	if err != nil {
+		return 0, err
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return 0, fmt.Errorf("refusing to send webhook to %s URL", req.URL.Scheme)
+	}
+
+	now := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "employee-api-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(job.ID, 10))
+	req.Header.Set("X-Webhook-Event", job.EventType)
+	req.Header.Set("X-Webhook-Attempt", strconv.Itoa(job.Attempts+1))
+	req.Header.Set("X-Webhook-Timestamp", strconv.FormatInt(now.Unix(), 10))
+	req.Header.Set("X-Webhook-Signature", webhookEntity.Sign(job.Secret, now, job.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		var urlErr interface{ Timeout() bool }
+		if errors.As(err, &urlErr) && urlErr.Timeout() {
+			return 0, fmt.Errorf("webhook did not answer within %s", d.cfg.Timeout)
+		}
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// backoff doubles from the base delay up to the configured maximum.
+func (d *Deliverer) backoff(attempts int) time.Duration {
+	wait := d.cfg.RetryBase
+	for i := 0; i < attempts && wait < d.cfg.RetryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.RetryMax)
+}
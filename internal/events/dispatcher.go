@@ -0,0 +1,195 @@
+// Package events publishes employee change events from the outbox table to
+// sinks.
+package events
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"go.uber.org/zap"
+)
+
+// lockKey identifies the advisory lock of the active dispatcher. Only one
+// replica dispatches at a time, otherwise two replicas could publish
+// consecutive events of one employee out of order.
+const lockKey int64 = 0x6f7574626f78
+
+// Sink receives published events. Publish must be idempotent on the event
+// ID: delivery is at least once, so an event can arrive again after a crash
+// or when another sink failed.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, evt eventEntity.Event) error
+}
+
+type Dispatcher struct {
+	db     *sql.DB
+	store  repository.OutboxRepository
+	sinks  []Sink
+	cfg    config.OutboxConfig
+	logger *zap.SugaredLogger
+}
+
+func NewDispatcher(
+	db *sql.DB,
+	store repository.OutboxRepository,
+	sinks []Sink,
+	cfg config.OutboxConfig,
+	logger *zap.SugaredLogger,
+) *Dispatcher {
+	return &Dispatcher{
+		db:     db,
+		store:  store,
+		sinks:  sinks,
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// Run dispatches until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		conn, err := d.lead(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.logger.Warnw("outbox dispatcher could not take the lock", "error", err)
+			}
+			sleep(ctx, d.cfg.PollInterval)
+			continue
+		}
+		if conn == nil {
+			// Another replica dispatches.
+			sleep(ctx, d.cfg.PollInterval)
+			continue
+		}
+
+		d.logger.Info("outbox dispatcher started")
+		d.dispatch(ctx, conn)
+		conn.Close()
+	}
+}
+
+// lead tries to take the dispatcher lock on a dedicated connection, which
+// holds it for as long as the connection lives. It returns nil when another
+// replica holds the lock.
+func (d *Dispatcher) lead(ctx context.Context) (*sql.Conn, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, lockKey).Scan(&locked); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if !locked {
+		conn.Close()
+		return nil, nil
+	}
+	return conn, nil
+}
+
+// dispatch publishes batches while the lock connection stays healthy.
+func (d *Dispatcher) dispatch(ctx context.Context, lock *sql.Conn) {
+	lastCleanup := time.Time{}
+
+	for ctx.Err() == nil {
+		if err := lock.PingContext(ctx); err != nil {
+			if ctx.Err() == nil {
+				d.logger.Warnw("outbox dispatcher lost its lock connection", "error", err)
+			}
+			return
+		}
+
+		published, err := d.publishBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			d.logger.Errorw("failed to read outbox", "error", err)
+		}
+
+		if time.Since(lastCleanup) > time.Hour {
+			lastCleanup = time.Now()
+			if removed, err := d.store.DeletePublished(ctx, d.cfg.Retention); err != nil {
+				d.logger.Warnw("failed to clean up outbox", "error", err)
+			} else if removed > 0 {
+				d.logger.Infow("cleaned up outbox", "removed", removed)
+			}
+		}
+
+		// A full batch means more is probably waiting.
+		if published < d.cfg.BatchSize {
+			sleep(ctx, d.cfg.PollInterval)
+		}
+	}
+}
+
+func (d *Dispatcher) publishBatch(ctx context.Context) (int, error) {
+	pending, err := d.store.GetPending(ctx, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, evt := range pending {
+		if err := d.publish(ctx, evt); err != nil {
+			if ctx.Err() != nil {
+				return published, nil
+			}
+
+			retryIn := d.backoff(evt.Attempts)
+			d.logger.Warnw("failed to publish event",
+				"event_id", evt.ID,
+				"type", evt.Type,
+				"employee_id", evt.EmployeeID,
+				"attempt", evt.Attempts+1,
+				"retry_in", retryIn.String(),
+				"error", err,
+			)
+			if err := d.store.MarkFailed(ctx, evt.ID, err, retryIn); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := d.store.MarkPublished(ctx, evt.ID); err != nil {
+			return published, err
+		}
+		published++
+	}
+	return published, nil
+}
+
+// publish hands evt to every sink. A failing sink fails the event, so the
+// sinks that succeeded will see it again on the retry.
+func (d *Dispatcher) publish(ctx context.Context, evt eventEntity.Event) error {
+	var errs []error
+	for _, sink := range d.sinks {
+		if err := sink.Publish(ctx, evt); err != nil {
+			errs = append(errs, errors.New(sink.Name()+": "+err.Error()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backoff doubles from the poll interval up to the configured maximum.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.PollInterval
+	for i := 0; i < attempts && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.MaxBackoff)
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,414 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// changesChannel is notified by the employees trigger after it recorded a
+// change.
+const changesChannel = "employee_changes"
+
+// feedBatchSize is how many changes are read from the log at once.
+const feedBatchSize = 500
+
+// Bounds of the delay before the listener is rebuilt after it failed to
+// connect.
+const (
+	listenerMinBackoff = time.Second
+	listenerMaxBackoff = time.Minute
+)
+
+var (
+	ErrSlowClient     = errors.New("client fell too far behind")
+	ErrFeedClosed     = errors.New("change feed is shutting down")
+	ErrTooManyClients = errors.New("too many event stream clients")
+	// ErrHistoryGone means changes after the requested id were already
+	// removed from the log.
+	ErrHistoryGone = errors.New("changes after this id are no longer kept")
+)
+
+// Feed follows the employee change log and fans new changes out to the
+// connected stream clients. Every client has a buffer of its own; one that
+// falls further behind is dropped instead of slowing the others down, and
+// resumes from the log when it reconnects.
+type Feed struct {
+	store  repository.ChangeRepository
+	dsn    func() (string, error)
+	cfg    config.StreamConfig
+	logger *zap.SugaredLogger
+
+	mu      sync.Mutex
+	clients map[*Client]struct{}
+	closed  bool
+	// published is the id up to which changes were broadcast, -1 until
+	// Run started.
+	published int64
+
+	// gap is the missing change Run waits for, only used by Run.
+	gap gap
+}
+
+// gap is a change id missing from the log while later ones are there.
+// Every transaction that can still commit it had started before xmax.
+type gap struct {
+	id   int64
+	xmax int64
+}
+
+// Client receives changes until Events is closed, Err then tells why.
+type Client struct {
+	events chan eventEntity.Event
+	err    error
+}
+
+func (c *Client) Events() <-chan eventEntity.Event { return c.events }
+
+// Err returns ErrSlowClient or ErrFeedClosed once Events is closed by the
+// feed, nil otherwise.
+func (c *Client) Err() error { return c.err }
+
+func NewFeed(
+	store repository.ChangeRepository,
+	dsn func() (string, error),
+	cfg config.StreamConfig,
+	logger *zap.SugaredLogger,
+) *Feed {
+	return &Feed{
+		store:     store,
+		dsn:       dsn,
+		cfg:       cfg,
+		logger:    logger,
+		clients:   map[*Client]struct{}{},
+		published: -1,
+	}
+}
+
+// Subscribe registers a client for changes recorded from now on.
+func (f *Feed) Subscribe() (*Client, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return nil, ErrFeedClosed
+	}
+	if len(f.clients) >= f.cfg.MaxClients {
+		return nil, ErrTooManyClients
+	}
+
+	c := &Client{events: make(chan eventEntity.Event, f.cfg.ClientBuffer)}
+	f.clients[c] = struct{}{}
+	return c, nil
+}
+
+func (f *Feed) Unsubscribe(c *Client) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.drop(c, nil)
+}
+
+// Close disconnects every client and refuses new ones. It is registered
+// with http.Server.RegisterOnShutdown: Shutdown waits for handlers to
+// return, which streams never do on their own.
+func (f *Feed) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	for c := range f.clients {
+		f.drop(c, ErrFeedClosed)
+	}
+}
+
+// drop must be called with mu held.
+func (f *Feed) drop(c *Client, err error) {
+	if _, ok := f.clients[c]; !ok {
+		return
+	}
+	delete(f.clients, c)
+	c.err = err
+	close(c.events)
+}
+
+// Replay hands send the changes after afterID that are still in the log,
+// oldest first, and returns the id of the last one. It stops at the last
+// change broadcast so far; later ones reach subscribed clients through
+// Events, and a change that commits out of order is not skipped.
+func (f *Feed) Replay(ctx context.Context, afterID int64, send func(eventEntity.Event) error) (int64, error) {
+	oldest, _, err := f.store.GetBounds(ctx)
+	if err != nil {
+		return afterID, err
+	}
+	if oldest > afterID+1 {
+		return afterID, ErrHistoryGone
+	}
+
+	for {
+		changes, err := f.store.GetSince(ctx, afterID, feedBatchSize)
+		if err != nil {
+			return afterID, err
+		}
+		published := f.publishedID()
+		for _, evt := range changes {
+			if published >= 0 && evt.ID > published {
+				return afterID, nil
+			}
+			if err := send(evt); err != nil {
+				return afterID, err
+			}
+			afterID = evt.ID
+		}
+		if len(changes) < feedBatchSize {
+			return afterID, nil
+		}
+	}
+}
+
+func (f *Feed) publishedID() int64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.published
+}
+
+// Run listens for change notifications until ctx is cancelled. Polling
+// covers the time without a listener. When a connection attempt fails the
+// listener is rebuilt with a fresh DSN, which may carry new credentials,
+// backing off up to listenerMaxBackoff.
+func (f *Feed) Run(ctx context.Context) {
+	lastID, ok := f.start(ctx)
+	if !ok {
+		return
+	}
+	f.mu.Lock()
+	f.published = lastID
+	f.mu.Unlock()
+	f.logger.Infow("change feed started", "last_id", lastID)
+
+	var listener *feedListener
+	defer func() { listener.close() }()
+	backoff := listenerMinBackoff
+	reconnect := time.NewTimer(0)
+	defer reconnect.Stop()
+
+	poll := time.NewTicker(f.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(time.Hour)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-reconnect.C:
+			var err error
+			if listener, err = f.listen(ctx); err != nil {
+				f.logger.Errorw("change feed cannot connect", "error", err, "retry_in", backoff.String())
+				reconnect.Reset(backoff)
+				backoff = min(2*backoff, listenerMaxBackoff)
+			}
+			continue
+		case <-listener.failedC():
+			listener.close()
+			listener = nil
+			f.logger.Infow("rebuilding change feed listener", "in", backoff.String())
+			reconnect.Reset(backoff)
+			backoff = min(2*backoff, listenerMaxBackoff)
+			continue
+		case <-listener.connectedC():
+			backoff = listenerMinBackoff
+			continue
+		case <-listener.notifyC():
+			// A nil notification follows a reconnect; read the log either
+			// way to catch up on whatever happened meanwhile.
+		case <-poll.C:
+		case <-cleanup.C:
+			if removed, err := f.store.DeleteOlderThan(ctx, f.cfg.Retention); err != nil {
+				f.logger.Warnw("failed to clean up change log", "error", err)
+			} else if removed > 0 {
+				f.logger.Infow("cleaned up change log", "removed", removed)
+			}
+			continue
+		}
+
+		lastID = f.catchUp(ctx, lastID)
+	}
+}
+
+// feedListener is a pq.Listener with its connection events as channels.
+// The methods of a nil feedListener return nil channels, which block.
+type feedListener struct {
+	*pq.Listener
+	failed    chan struct{}
+	connected chan struct{}
+}
+
+func (l *feedListener) notifyC() <-chan *pq.Notification {
+	if l == nil {
+		return nil
+	}
+	return l.Notify
+}
+
+func (l *feedListener) failedC() <-chan struct{} {
+	if l == nil {
+		return nil
+	}
+	return l.failed
+}
+
+func (l *feedListener) connectedC() <-chan struct{} {
+	if l == nil {
+		return nil
+	}
+	return l.connected
+}
+
+func (l *feedListener) close() {
+	if l != nil {
+		l.Close()
+	}
+}
+
+// listen starts a listener on the changes channel with a fresh DSN.
+func (f *Feed) listen(ctx context.Context) (*feedListener, error) {
+	dsn, err := f.dsn()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &feedListener{
+		failed:    make(chan struct{}, 1),
+		connected: make(chan struct{}, 1),
+	}
+	signal := func(c chan struct{}) {
+		select {
+		case c <- struct{}{}:
+		default:
+		}
+	}
+	l.Listener = pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		switch ev {
+		case pq.ListenerEventConnected:
+			signal(l.connected)
+		case pq.ListenerEventDisconnected:
+			f.logger.Warnw("change feed listener disconnected", "error", err)
+		case pq.ListenerEventConnectionAttemptFailed:
+			f.logger.Warnw("change feed listener failed to connect", "error", err)
+			signal(l.failed)
+		case pq.ListenerEventReconnected:
+			f.logger.Info("change feed listener reconnected")
+			signal(l.connected)
+		}
+	})
+	// Listen blocks until the listener is connected; polling covers the
+	// meantime. Close releases it.
+	go func() {
+		if err := l.Listen(changesChannel); err != nil && ctx.Err() == nil && err.Error() != `pq: Listener has been closed` {
+			f.logger.Errorw("change feed cannot listen", "error", err)
+		}
+	}()
+	return l, nil
+}
+
+// start returns the newest change id, retrying until the log is readable.
+func (f *Feed) start(ctx context.Context) (int64, bool) {
+	for {
+		_, latest, err := f.store.GetBounds(ctx)
+		if err == nil {
+			return latest, true
+		}
+		if ctx.Err() != nil {
+			return 0, false
+		}
+		f.logger.Warnw("change feed cannot read the change log", "error", err)
+		sleep(ctx, f.cfg.PollInterval)
+	}
+}
+
+// catchUp broadcasts the changes after lastID and returns the new last id.
+//
+// Change ids are taken in the order rows are written but become visible as
+// transactions commit, so the log can miss an id while later ones are
+// there. catchUp stops at such a gap and notes the snapshot after the read:
+// the writer of the missing id had started by then. Once the oldest running
+// transaction is younger than that snapshot, the writer has ended, and a
+// read started after that would have seen the change, so it was rolled back
+// and the gap is skipped.
+func (f *Feed) catchUp(ctx context.Context, lastID int64) int64 {
+	for {
+		// Taken before the read, so a change committed in between is read
+		// rather than skipped.
+		xmin := int64(-1)
+		if f.gap.id == lastID+1 {
+			var err error
+			if xmin, _, err = f.store.GetSnapshot(ctx); err != nil {
+				if ctx.Err() == nil {
+					f.logger.Errorw("failed to read change log", "error", err)
+				}
+				return lastID
+			}
+		}
+
+		changes, err := f.store.GetSince(ctx, lastID, feedBatchSize)
+		if err != nil {
+			if ctx.Err() == nil {
+				f.logger.Errorw("failed to read change log", "error", err)
+			}
+			return lastID
+		}
+
+		waiting := false
+		for _, evt := range changes {
+			if evt.ID != lastID+1 {
+				if f.gap.id != lastID+1 {
+					waiting = true
+					break
+				}
+				if xmin < f.gap.xmax {
+					return lastID
+				}
+				f.logger.Debugw("change feed skipped rolled back changes", "from", lastID+1, "to", evt.ID-1)
+			}
+			f.broadcast(evt)
+			lastID = evt.ID
+		}
+		if waiting {
+			_, xmax, err := f.store.GetSnapshot(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					f.logger.Errorw("failed to read change log", "error", err)
+				}
+				return lastID
+			}
+			// Look again right away, the writer may be done already.
+			f.gap = gap{id: lastID + 1, xmax: xmax}
+			continue
+		}
+		if len(changes) < feedBatchSize {
+			return lastID
+		}
+	}
+}
+
+func (f *Feed) broadcast(evt eventEntity.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.published = evt.ID
+
+	for c := range f.clients {
+		select {
+		case c.events <- evt:
+		default:
+			f.drop(c, ErrSlowClient)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+)
+
+// WriterSink writes one JSON object per line, to stdout for example.
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func NewStdoutSink() *WriterSink {
+	return &WriterSink{name: "stdout", w: os.Stdout}
+}
+
+func (s *WriterSink) Name() string { return s.name }
+
+func (s *WriterSink) Publish(ctx context.Context, evt eventEntity.Event) error {
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// FileSink appends JSON lines to a file and syncs after every event, so an
+// event marked published is on disk.
+type FileSink struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func NewFileSink(path string) (*FileSink, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open event file: %w", err)
+	}
+	return &FileSink{file: file}, nil
+}
+
+func (s *FileSink) Name() string { return "file" }
+
+func (s *FileSink) Publish(ctx context.Context, evt eventEntity.Event) error {
+	line, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *FileSink) Close() error {
+	return s.file.Close()
+}
+
+// WebhookSink POSTs each event as JSON to a fixed URL. Any status other
+// than 2xx is a failure and the event is retried.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookSink(url string, timeout time.Duration) *WebhookSink {
+	return &WebhookSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+func (s *WebhookSink) Name() string { return "webhook" }
+
+func (s *WebhookSink) Publish(ctx context.Context, evt eventEntity.Event) error {
+	body, err := json.Marshal(evt)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-ID", strconv.FormatInt(evt.ID, 10))
+	req.Header.Set("X-Event-Type", evt.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
+
+// NewSinks builds the sinks named in cfg.Sinks. The returned function
+// closes the ones holding files.
+func NewSinks(cfg config.OutboxConfig) ([]Sink, func() error, error) {
+	var (
+		sinks   []Sink
+		closers []io.Closer
+	)
+	closeAll := func() error {
+		var errs []error
+		for _, c := range closers {
+			errs = append(errs, c.Close())
+		}
+		return errors.Join(errs...)
+	}
+
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "stdout":
+			sinks = append(sinks, NewStdoutSink())
+		case "file":
+			sink, err := NewFileSink(cfg.File)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			sinks = append(sinks, sink)
+			closers = append(closers, sink)
+		case "webhook":
+			sinks = append(sinks, NewWebhookSink(cfg.WebhookURL, cfg.WebhookTimeout))
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("unknown event sink %q", name)
+		}
+	}
+	return sinks, closeAll, nil
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/migrate"
+)
+
+// Migrations fails while the database is behind (or ahead of) the
+// migrations compiled into the binary.
+func Migrations(migrator *migrate.Migrator) Checker {
+	return CheckFunc(func(ctx context.Context) error {
+		version, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if expected := migrator.Latest(); version != expected {
+			return fmt.Errorf("database is at migration %d, expected %d", version, expected)
+		}
+		return nil
+	})
+}
+
+// DiskSpace fails when the file system holding dir has less than minFree
+// bytes available.
+func DiskSpace(dir string, minFree uint64) Checker {
+	return CheckFunc(func(ctx context.Context) error {
+		free, err := freeBytes(dir)
+		if err != nil {
+			return err
+		}
+		if free < minFree {
+			return fmt.Errorf("%d bytes free in %s, need at least %d", free, dir, minFree)
+		}
+		return nil
+	})
+}
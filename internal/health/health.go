@@ -0,0 +1,150 @@
+// Package health runs named dependency checks for the liveness and
+// readiness endpoints.
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Checker reports a dependency as unhealthy by returning an error.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckFunc adapts a function to Checker.
+type CheckFunc func(ctx context.Context) error
+
+func (f CheckFunc) Check(ctx context.Context) error { return f(ctx) }
+
+type Result struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type namedChecker struct {
+	name    string
+	checker Checker
+}
+
+// Registry runs its checks at most once per cache TTL, however many probes
+// arrive, so a burst of health checks cannot stampede the database.
+type Registry struct {
+	timeout time.Duration
+	ttl     time.Duration
+
+	checks       []namedChecker
+	shuttingDown atomic.Bool
+
+	mu      sync.Mutex
+	cached  Report
+	checked time.Time
+}
+
+// NewRegistry bounds every check by timeout and reuses results for ttl.
+func NewRegistry(timeout, ttl time.Duration) *Registry {
+	return &Registry{timeout: timeout, ttl: ttl}
+}
+
+// Register adds a check. It is not safe to call once the registry serves
+// requests.
+func (r *Registry) Register(name string, checker Checker) {
+	r.checks = append(r.checks, namedChecker{name: name, checker: checker})
+}
+
+// Shutdown makes every later report fail, so load balancers stop routing to
+// the instance while in-flight requests drain.
+func (r *Registry) Shutdown() {
+	r.shuttingDown.Store(true)
+}
+
+// Run returns the cached report or runs every check concurrently.
+func (r *Registry) Run(ctx context.Context) Report {
+	if r.shuttingDown.Load() {
+		return Report{
+			Status: StatusFailing,
+			Checks: map[string]Result{"shutdown": {Status: StatusFailing, Error: "server is shutting down", CheckedAt: time.Now()}},
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.checked.IsZero() && time.Since(r.checked) < r.ttl {
+		return r.cached
+	}
+
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(r.checks))}
+	results := make([]Result, len(r.checks))
+
+	var wg sync.WaitGroup
+	for i, c := range r.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.runOne(ctx, c.checker)
+		}()
+	}
+	wg.Wait()
+
+	for i, c := range r.checks {
+		report.Checks[c.name] = results[i]
+		if results[i].Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+
+	r.cached = report
+	r.checked = time.Now()
+	return report
+}
+
+func (r *Registry) runOne(ctx context.Context, checker Checker) Result {
+	// Checks outlive the probe that triggered them, since other probes
+	// share the cached result.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := Result{
+		Status:     StatusOK,
+		DurationMs: time.Since(start).Milliseconds(),
+		CheckedAt:  start,
+	}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// Handler writes the report, with 503 when any check fails.
+func (r *Registry) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		report := r.Run(req.Context())
+		status := http.StatusOK
+		if report.Status != StatusOK {
+			status = http.StatusServiceUnavailable
+		}
+		w.Header().Set("Cache-Control", "no-store")
+		protocol.WriteJSON(w, status, report)
+	}
+}
@@ -0,0 +1,125 @@
+// Package logging builds the zap logger, whose level and format can be
+// changed while the server runs, and carries request-scoped loggers in the
+// context.
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	FormatJSON    = "json"
+	FormatConsole = "console"
+)
+
+// Logger owns the settings the admin endpoint can change at runtime.
+type Logger struct {
+	*zap.SugaredLogger
+	level   zap.AtomicLevel
+	console *atomic.Bool
+}
+
+// New builds a logger writing to stderr at level ("debug", "info", ...) in
+// format, either json or console.
+func New(level, format string) (*Logger, error) {
+	atomicLevel, err := zap.ParseAtomicLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Logger{level: atomicLevel, console: &atomic.Bool{}}
+	if err := l.SetFormat(format); err != nil {
+		return nil, err
+	}
+
+	jsonConfig := zap.NewProductionEncoderConfig()
+	jsonConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	consoleConfig := zap.NewDevelopmentEncoderConfig()
+	out := zapcore.Lock(os.Stderr)
+
+	core := &switchCore{
+		level:   atomicLevel,
+		console: l.console,
+		json:    zapcore.NewCore(zapcore.NewJSONEncoder(jsonConfig), out, zapcore.DebugLevel),
+		text:    zapcore.NewCore(zapcore.NewConsoleEncoder(consoleConfig), out, zapcore.DebugLevel),
+	}
+	l.SugaredLogger = zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)).Sugar()
+	return l, nil
+}
+
+func (l *Logger) Level() string { return l.level.Level().String() }
+
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := zapcore.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	l.level.SetLevel(parsed)
+	return nil
+}
+
+func (l *Logger) Format() string {
+	if l.console.Load() {
+		return FormatConsole
+	}
+	return FormatJSON
+}
+
+func (l *Logger) SetFormat(format string) error {
+	switch format {
+	case FormatJSON:
+		l.console.Store(false)
+	case FormatConsole:
+		l.console.Store(true)
+	default:
+		return fmt.Errorf("unknown log format %q, expected json or console", format)
+	}
+	return nil
+}
+
+// switchCore writes through the JSON or the console core depending on the
+// current format. Both carry the same fields, so loggers derived with With
+// keep their fields across a format switch.
+type switchCore struct {
+	level   zap.AtomicLevel
+	console *atomic.Bool
+	json    zapcore.Core
+	text    zapcore.Core
+}
+
+func (c *switchCore) Enabled(level zapcore.Level) bool {
+	return c.level.Enabled(level)
+}
+
+func (c *switchCore) With(fields []zapcore.Field) zapcore.Core {
+	return &switchCore{
+		level:   c.level,
+		console: c.console,
+		json:    c.json.With(fields),
+		text:    c.text.With(fields),
+	}
+}
+
+func (c *switchCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+func (c *switchCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	if c.console.Load() {
+		return c.text.Write(entry, fields)
+	}
+	return c.json.Write(entry, fields)
+}
+
+func (c *switchCore) Sync() error {
+	return errors.Join(c.json.Sync(), c.text.Sync())
+}
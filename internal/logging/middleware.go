@@ -0,0 +1,98 @@
+package logging
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type loggerKey struct{}
+
+// requestLog is shared by every context derived from one request, so fields
+// added deep in the chain (such as the principal) reach the access log.
+type requestLog struct {
+	mu     sync.Mutex
+	logger *zap.SugaredLogger
+	fields []any
+}
+
+// FromContext returns the request-scoped logger, or fallback outside a
+// request.
+func FromContext(ctx context.Context, fallback *zap.SugaredLogger) *zap.SugaredLogger {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLog); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return rl.logger
+	}
+	return fallback
+}
+
+// AddFields attaches key/value pairs to the request-scoped logger and to the
+// access log line of the request.
+func AddFields(ctx context.Context, keysAndValues ...any) {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLog); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(keysAndValues...)
+		rl.fields = append(rl.fields, keysAndValues...)
+	}
+}
+
+// Middleware puts a logger carrying the request ID, method and path into the
+// context and writes one access log line per request. It must run after
+// middleware.RequestID.
+func Middleware(logger *zap.SugaredLogger) func(http.Handler) http.Handler {
+	// Access lines are the same for every request, callers and stack
+	// traces would only add noise.
+	access := logger.Desugar().WithOptions(zap.WithCaller(false), zap.AddStacktrace(zapcore.FatalLevel)).Sugar()
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			base := []any{
+				"request_id", middleware.GetReqID(r.Context()),
+				"method", r.Method,
+				"path", r.URL.Path,
+			}
+			rl := &requestLog{logger: logger.With(base...)}
+
+			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), loggerKey{}, rl)))
+
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := ""
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				route = rctx.RoutePattern()
+			}
+
+			rl.mu.Lock()
+			fields := append(base,
+				"route", route,
+				"status", status,
+				"bytes", ww.BytesWritten(),
+				"latency_ms", float64(time.Since(start).Microseconds())/1000,
+				"remote_addr", r.RemoteAddr,
+			)
+			fields = append(fields, rl.fields...)
+			rl.mu.Unlock()
+
+			switch {
+			case status >= http.StatusInternalServerError:
+				access.Errorw("request", fields...)
+			case status >= http.StatusBadRequest:
+				access.Warnw("request", fields...)
+			default:
+				access.Infow("request", fields...)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package cached
+
+import (
+	"context"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+type attributeRepository struct {
+	next      repository.AttributeRepository
+	employees *EmployeeRepository
+}
+
+// NewAttributeRepository clears the employees cached by employees whenever a
+// definition is deleted, since that strips the attribute from every employee
+// holding it.
+func NewAttributeRepository(next repository.AttributeRepository, employees *EmployeeRepository) repository.AttributeRepository {
+	return &attributeRepository{next: next, employees: employees}
+}
+
+func (r *attributeRepository) GetDefinitions(ctx context.Context) ([]attributeEntity.Definition, error) {
+	return r.next.GetDefinitions(ctx)
+}
+
+func (r *attributeRepository) CreateDefinition(ctx context.Context, def *attributeEntity.Definition) error {
+	return r.next.CreateDefinition(ctx, def)
+}
+
+func (r *attributeRepository) DeleteDefinition(ctx context.Context, id int64) error {
+	err := r.next.DeleteDefinition(ctx, id)
+	r.employees.Clear(ctx)
+	return err
+}
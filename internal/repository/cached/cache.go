@@ -0,0 +1,101 @@
+package cached
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+)
+
+// EmployeeCache stores employees by ID for NewEmployeeRepository. LRU keeps
+// them in process; a cache shared between instances, e.g. Redis, only needs
+// to implement these methods to take its place. Implementations are safe for
+// concurrent use and treat their own failures as misses, the database is
+// still there.
+type EmployeeCache interface {
+	Get(ctx context.Context, id int64) (*employeeEntity.Employee, bool)
+	Set(ctx context.Context, employee *employeeEntity.Employee)
+	Delete(ctx context.Context, id int64)
+	Clear(ctx context.Context)
+}
+
+// LRU is an in-process EmployeeCache holding up to size employees for at
+// most ttl each, dropping the least recently used one when full.
+type LRU struct {
+	size int
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	order   *list.List
+	entries map[int64]*list.Element
+}
+
+type lruEntry struct {
+	employee *employeeEntity.Employee
+	expires  time.Time
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	return &LRU{
+		size:    size,
+		ttl:     ttl,
+		order:   list.New(),
+		entries: make(map[int64]*list.Element, size),
+	}
+}
+
+func (c *LRU) Get(_ context.Context, id int64) (*employeeEntity.Employee, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[id]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*lruEntry)
+	if !time.Now().Before(entry.expires) {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.employee, true
+}
+
+func (c *LRU) Set(_ context.Context, employee *employeeEntity.Employee) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{employee: employee, expires: time.Now().Add(c.ttl)}
+	if elem, ok := c.entries[employee.ID]; ok {
+		elem.Value = entry
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[employee.ID] = c.order.PushFront(entry)
+	for c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).employee.ID)
+	}
+}
+
+func (c *LRU) Delete(_ context.Context, id int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[id]; ok {
+		c.order.Remove(elem)
+		delete(c.entries, id)
+	}
+}
+
+func (c *LRU) Clear(context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.order.Init()
+	clear(c.entries)
+}
@@ -0,0 +1,145 @@
+// Package cached decorates repositories with a read-through cache, leaving
+// the Postgres stores themselves untouched.
+package cached
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/metrics"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"golang.org/x/sync/singleflight"
+)
+
+// EmployeeRepository is the cached repository.EmployeeRepository made by
+// NewEmployeeRepository.
+type EmployeeRepository struct {
+	next    repository.EmployeeRepository
+	cache   EmployeeCache
+	metrics *metrics.Metrics
+	loads   singleflight.Group
+
+	// writes counts invalidations. A load that overlaps one may have read
+	// the old row, so it is not cached, and callers after it do not join
+	// it.
+	mu     sync.Mutex
+	writes uint64
+}
+
+// NewEmployeeRepository keeps employees read with GetById in cache, and
+// concurrent misses of one ID share a single query. Update drops the
+// employee from the cache; Delete clears all of it, as the store also unsets
+// the manager of the deleted employee's reports. Other stores changing
+// employees call Clear, see NewAttributeRepository. Writes of other
+// instances when the cache is not shared are seen once entries expire.
+func NewEmployeeRepository(next repository.EmployeeRepository, cache EmployeeCache, m *metrics.Metrics) *EmployeeRepository {
+	return &EmployeeRepository{next: next, cache: cache, metrics: m}
+}
+
+func (r *EmployeeRepository) GetAll(ctx context.Context, filter employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	return r.next.GetAll(ctx, filter)
+}
+
+func (r *EmployeeRepository) GetById(ctx context.Context, id int64) (*employeeEntity.Employee, error) {
+	if employee, ok := r.cache.Get(ctx, id); ok {
+		r.metrics.ObserveCache("employee", true)
+		return clone(employee), nil
+	}
+	r.metrics.ObserveCache("employee", false)
+
+	r.mu.Lock()
+	writes := r.writes
+	r.mu.Unlock()
+
+	loaded := r.loads.DoChan(fmt.Sprintf("%d@%d", id, writes), func() (any, error) {
+		// The query is shared by every waiting caller, so one giving up
+		// must not fail it for the others; the store bounds it itself.
+		employee, err := r.next.GetById(context.WithoutCancel(ctx), id)
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.writes == writes {
+			r.cache.Set(ctx, clone(employee))
+		}
+		return employee, nil
+	})
+
+	select {
+	case res := <-loaded:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return clone(res.Val.(*employeeEntity.Employee)), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (r *EmployeeRepository) Create(ctx context.Context, employee *employeeEntity.Employee) error {
+	return r.next.Create(ctx, employee)
+}
+
+func (r *EmployeeRepository) Update(ctx context.Context, employee *employeeEntity.Employee) error {
+	err := r.next.Update(ctx, employee)
+	r.invalidate(func() { r.cache.Delete(context.WithoutCancel(ctx), employee.ID) })
+	return err
+}
+
+func (r *EmployeeRepository) Delete(ctx context.Context, id int64) error {
+	err := r.next.Delete(ctx, id)
+	r.Clear(ctx)
+	return err
+}
+
+// Clear drops every cached employee, for writes to employees made through
+// other repositories.
+func (r *EmployeeRepository) Clear(ctx context.Context) {
+	r.invalidate(func() { r.cache.Clear(context.WithoutCancel(ctx)) })
+}
+
+// invalidate runs drop once a write is done. Loads that started before it
+// may have read the old row and no longer cache what they read. Failed
+// writes invalidate too: a missing employee may still be cached from before
+// another instance deleted it.
+func (r *EmployeeRepository) invalidate(drop func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writes++
+	drop()
+}
+
+// clone copies employee so callers cannot change what is cached.
+func clone(employee *employeeEntity.Employee) *employeeEntity.Employee {
+	c := *employee
+	if employee.ManagerID != nil {
+		managerID := *employee.ManagerID
+		c.ManagerID = &managerID
+	}
+	if employee.Attributes != nil {
+		c.Attributes = cloneValue(employee.Attributes).(map[string]any)
+	}
+	return &c
+}
+
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, value := range v {
+			out[key] = cloneValue(value)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, value := range v {
+			out[i] = cloneValue(value)
+		}
+		return out
+	}
+	return v
+}
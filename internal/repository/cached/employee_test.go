@@ -0,0 +1,313 @@
+package cached
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/metrics"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+// store is an in-memory repository.EmployeeRepository counting reads. With
+// hold set, GetById signals loaded once it has read the row and waits for
+// hold before returning it, so a test can write in between.
+type store struct {
+	mu     sync.Mutex
+	rows   map[int64]employeeEntity.Employee
+	reads  int
+	loaded chan struct{}
+	hold   chan struct{}
+}
+
+func newStore(names ...string) *store {
+	s := &store{rows: make(map[int64]employeeEntity.Employee)}
+	for i, name := range names {
+		id := int64(i + 1)
+		s.rows[id] = employeeEntity.Employee{ID: id, Name: name}
+	}
+	return s
+}
+
+func (s *store) GetAll(context.Context, employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *store) GetById(_ context.Context, id int64) (*employeeEntity.Employee, error) {
+	s.mu.Lock()
+	s.reads++
+	row, ok := s.rows[id]
+	loaded, hold := s.loaded, s.hold
+	s.mu.Unlock()
+
+	if loaded != nil {
+		loaded <- struct{}{}
+		<-hold
+	}
+	if !ok {
+		return nil, repository.ErrNotFound
+	}
+	return &row, nil
+}
+
+func (s *store) Create(context.Context, *employeeEntity.Employee) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) Update(_ context.Context, employee *employeeEntity.Employee) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rows[employee.ID] = *employee
+	return nil
+}
+
+func (s *store) Delete(_ context.Context, id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.rows, id)
+	return nil
+}
+
+func (s *store) readCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reads
+}
+
+type attributes struct {
+	err error
+}
+
+func (a *attributes) GetDefinitions(context.Context) ([]attributeEntity.Definition, error) {
+	return nil, nil
+}
+
+func (a *attributes) CreateDefinition(context.Context, *attributeEntity.Definition) error {
+	return nil
+}
+
+func (a *attributes) DeleteDefinition(context.Context, int64) error {
+	return a.err
+}
+
+func newRepository(next repository.EmployeeRepository, cache EmployeeCache) *EmployeeRepository {
+	return NewEmployeeRepository(next, cache, metrics.New("test", nil))
+}
+
+func TestGetByIdRacingWrite(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(ctx context.Context, r *EmployeeRepository) error
+		want  string
+	}{
+		{
+			name: "update",
+			write: func(ctx context.Context, r *EmployeeRepository) error {
+				return r.Update(ctx, &employeeEntity.Employee{ID: 1, Name: "Ada Byron"})
+			},
+			want: "Ada Byron",
+		},
+		{
+			name: "delete of a report",
+			write: func(ctx context.Context, r *EmployeeRepository) error {
+				return r.Delete(ctx, 2)
+			},
+			want: "Ada",
+		},
+		{
+			name: "clear",
+			write: func(ctx context.Context, r *EmployeeRepository) error {
+				r.Clear(ctx)
+				return nil
+			},
+			want: "Ada",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newStore("Ada", "Grace")
+			r := newRepository(s, NewLRU(10, time.Hour))
+
+			hold := make(chan struct{})
+			s.loaded, s.hold = make(chan struct{}), hold
+			done := make(chan error, 1)
+			go func() {
+				_, err := r.GetById(ctx, 1)
+				done <- err
+			}()
+
+			<-s.loaded
+			if err := tt.write(ctx, r); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			s.mu.Lock()
+			s.loaded, s.hold = nil, nil
+			s.mu.Unlock()
+			close(hold)
+			if err := <-done; err != nil {
+				t.Fatalf("racing GetById: %v", err)
+			}
+
+			got, err := r.GetById(ctx, 1)
+			if err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+			if got.Name != tt.want {
+				t.Errorf("name = %q, want %q", got.Name, tt.want)
+			}
+			if reads := s.readCount(); reads != 2 {
+				t.Errorf("store read %d times, want 2: the row read before the write was cached", reads)
+			}
+		})
+	}
+}
+
+func TestGetByIdExpiry(t *testing.T) {
+	tests := []struct {
+		name  string
+		ttl   time.Duration
+		wait  time.Duration
+		reads int
+	}{
+		{name: "fresh", ttl: time.Hour, reads: 1},
+		{name: "expired", ttl: 10 * time.Millisecond, wait: 20 * time.Millisecond, reads: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			s := newStore("Ada")
+			r := newRepository(s, NewLRU(10, tt.ttl))
+
+			if _, err := r.GetById(ctx, 1); err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+			time.Sleep(tt.wait)
+			if _, err := r.GetById(ctx, 1); err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+			if reads := s.readCount(); reads != tt.reads {
+				t.Errorf("store read %d times, want %d", reads, tt.reads)
+			}
+		})
+	}
+}
+
+func TestLRUEviction(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		gets    []int64
+		cached  []int64
+		evicted []int64
+	}{
+		{
+			name:    "oldest is dropped",
+			size:    2,
+			gets:    []int64{1, 2, 3},
+			cached:  []int64{2, 3},
+			evicted: []int64{1},
+		},
+		{
+			name:    "a hit keeps an employee",
+			size:    2,
+			gets:    []int64{1, 2, 1, 3},
+			cached:  []int64{1, 3},
+			evicted: []int64{2},
+		},
+		{
+			name:   "within size",
+			size:   3,
+			gets:   []int64{1, 2, 3},
+			cached: []int64{1, 2, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewLRU(tt.size, time.Hour)
+			r := newRepository(newStore("Ada", "Grace", "Edsger"), cache)
+
+			for _, id := range tt.gets {
+				if _, err := r.GetById(ctx, id); err != nil {
+					t.Fatalf("GetById(%d): %v", id, err)
+				}
+			}
+			// Checking the evicted ones first, as Get moves hits to the front.
+			for _, id := range tt.evicted {
+				if _, ok := cache.Get(ctx, id); ok {
+					t.Errorf("employee %d is cached, want it evicted", id)
+				}
+			}
+			for _, id := range tt.cached {
+				if _, ok := cache.Get(ctx, id); !ok {
+					t.Errorf("employee %d is not cached", id)
+				}
+			}
+		})
+	}
+}
+
+func TestAttributeRepositoryClear(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		call    func(ctx context.Context, r repository.AttributeRepository) error
+		cleared bool
+	}{
+		{
+			name: "delete",
+			call: func(ctx context.Context, r repository.AttributeRepository) error {
+				return r.DeleteDefinition(ctx, 1)
+			},
+			cleared: true,
+		},
+		{
+			name: "failed delete",
+			err:  repository.ErrNotFound,
+			call: func(ctx context.Context, r repository.AttributeRepository) error {
+				return r.DeleteDefinition(ctx, 1)
+			},
+			cleared: true,
+		},
+		{
+			name: "create",
+			call: func(ctx context.Context, r repository.AttributeRepository) error {
+				return r.CreateDefinition(ctx, &attributeEntity.Definition{})
+			},
+		},
+		{
+			name: "list",
+			call: func(ctx context.Context, r repository.AttributeRepository) error {
+				_, err := r.GetDefinitions(ctx)
+				return err
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			cache := NewLRU(10, time.Hour)
+			employees := newRepository(newStore("Ada"), cache)
+			if _, err := employees.GetById(ctx, 1); err != nil {
+				t.Fatalf("GetById: %v", err)
+			}
+
+			err := tt.call(ctx, NewAttributeRepository(&attributes{err: tt.err}, employees))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if _, ok := cache.Get(ctx, 1); ok == tt.cleared {
+				t.Errorf("employee cached = %v, want %v", ok, !tt.cleared)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+// Package instrumented decorates repositories with metrics, leaving the
+// Postgres stores themselves untouched.
+package instrumented
+
+import (
+	"context"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/metrics"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+type employeeRepository struct {
+	next    repository.EmployeeRepository
+	metrics *metrics.Metrics
+}
+
+func NewEmployeeRepository(next repository.EmployeeRepository, m *metrics.Metrics) repository.EmployeeRepository {
+	return &employeeRepository{next: next, metrics: m}
+}
+
+func (r *employeeRepository) GetAll(ctx context.Context, filter employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	start := time.Now()
+	employees, err := r.next.GetAll(ctx, filter)
+	r.metrics.ObserveQuery("employee", "GetAll", start, err)
+	return employees, err
+}
+
+func (r *employeeRepository) GetById(ctx context.Context, id int64) (*employeeEntity.Employee, error) {
+	start := time.Now()
+	employee, err := r.next.GetById(ctx, id)
+	r.metrics.ObserveQuery("employee", "GetById", start, err)
+	return employee, err
+}
+
+func (r *employeeRepository) Create(ctx context.Context, employee *employeeEntity.Employee) error {
+	start := time.Now()
+	err := r.next.Create(ctx, employee)
+	r.metrics.ObserveQuery("employee", "Create", start, err)
+	return err
+}
+
+func (r *employeeRepository) Update(ctx context.Context, employee *employeeEntity.Employee) error {
+	start := time.Now()
+	err := r.next.Update(ctx, employee)
+	r.metrics.ObserveQuery("employee", "Update", start, err)
+	return err
+}
+
+func (r *employeeRepository) Delete(ctx context.Context, id int64) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.metrics.ObserveQuery("employee", "Delete", start, err)
+	return err
+}
@@ -0,0 +1,141 @@
+package attachment
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+func NewAttachmentStore(db *sql.DB) *attachmentStore {
+	return &attachmentStore{
+		DB: db,
+	}
+}
+
+type attachmentStore struct {
+	DB *sql.DB
+}
+
+const attachmentColumns = `id, employee_id, filename, category, content_type, size_bytes, sha256, storage_key, uploaded_by, created_at`
+
+func scanAttachment(row interface{ Scan(...any) error }, att *attachmentEntity.Attachment) error {
+	return row.Scan(
+		&att.ID,
+		&att.EmployeeID,
+		&att.Filename,
+		&att.Category,
+		&att.ContentType,
+		&att.SizeBytes,
+		&att.SHA256,
+		&att.StorageKey,
+		&att.UploadedBy,
+		&att.CreatedAt,
+	)
+}
+
+func (a *attachmentStore) GetById(ctx context.Context, employeeID int64, id int64) (*attachmentEntity.Attachment, error) {
+	query := `SELECT ` + attachmentColumns + ` FROM attachments WHERE id = $1 AND employee_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var att attachmentEntity.Attachment
+	if err := scanAttachment(a.DB.QueryRowContext(ctx, query, id, employeeID), &att); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &att, nil
+}
+
+func (a *attachmentStore) GetByEmployee(ctx context.Context, employeeID int64) ([]attachmentEntity.Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE employee_id = $1
+		ORDER BY created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []attachmentEntity.Attachment{}
+	for rows.Next() {
+		var att attachmentEntity.Attachment
+		if err := scanAttachment(rows, &att); err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, att)
+	}
+
+	return attachments, rows.Err()
+}
+
+func (a *attachmentStore) Create(ctx context.Context, att *attachmentEntity.Attachment) error {
+	query := `
+		INSERT INTO attachments (employee_id, filename, category, content_type, size_bytes, sha256, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) RETURNING id, created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	err := a.DB.QueryRowContext(
+		ctx,
+		query,
+		att.EmployeeID,
+		att.Filename,
+		att.Category,
+		att.ContentType,
+		att.SizeBytes,
+		att.SHA256,
+		att.StorageKey,
+		att.UploadedBy,
+	).Scan(&att.ID, &att.CreatedAt)
+
+	if err != nil {
+		switch {
+		case err.Error() == `pq: insert or update on table "attachments" violates foreign key constraint "attachments_employee_id_fkey"`:
+			return repository.ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *attachmentStore) Delete(ctx context.Context, employeeID int64, id int64) error {
+	query := `DELETE FROM attachments WHERE id = $1 AND employee_id = $2`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := a.DB.ExecContext(ctx, query, id, employeeID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
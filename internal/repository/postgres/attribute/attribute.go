@@ -0,0 +1,155 @@
+package attribute
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/outbox"
+	"github.com/lib/pq"
+)
+
+func NewAttributeStore(db *sql.DB) *attributeStore {
+	return &attributeStore{
+		DB: db,
+	}
+}
+
+type attributeStore struct {
+	DB *sql.DB
+}
+
+func (a *attributeStore) GetDefinitions(ctx context.Context) ([]attributeEntity.Definition, error) {
+	query := `
+		SELECT id, name, type, required, enum_values, created_at
+		FROM attribute_definitions
+		ORDER BY name
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := a.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	defs := []attributeEntity.Definition{}
+	for rows.Next() {
+		var def attributeEntity.Definition
+		err := rows.Scan(&def.ID, &def.Name, &def.Type, &def.Required, pq.Array(&def.EnumValues), &def.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+
+	return defs, rows.Err()
+}
+
+func (a *attributeStore) CreateDefinition(ctx context.Context, def *attributeEntity.Definition) error {
+	query := `
+		INSERT INTO attribute_definitions (name, type, required, enum_values)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	err := a.DB.QueryRowContext(
+		ctx,
+		query,
+		def.Name,
+		def.Type,
+		def.Required,
+		pq.Array(def.EnumValues),
+	).Scan(&def.ID, &def.CreatedAt)
+
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "attribute_definitions_name_key"`:
+			return repository.ErrAttributeExists
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteDefinition removes the definition and strips its values from every
+// employee so they stay valid against the remaining definitions. Each
+// employee changed gets an employee.updated event like any other update.
+func (a *attributeStore) DeleteDefinition(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	return repository.WithTx(a.DB, ctx, func(tx *sql.Tx) error {
+		var name string
+		err := tx.QueryRowContext(ctx, `DELETE FROM attribute_definitions WHERE id = $1 RETURNING name`, id).Scan(&name)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+
+		emps, err := stripAttribute(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		for i := range emps {
+			if err := outbox.Enqueue(ctx, tx, eventEntity.EmployeeUpdated, emps[i].ID, emps[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// stripAttribute removes the attribute from every employee and returns the
+// changed rows. They are read in full before the events are written, the
+// connection cannot run another statement while rows are open.
+func stripAttribute(ctx context.Context, tx *sql.Tx, name string) ([]employeeEntity.Employee, error) {
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE employees SET attributes = attributes - $1, updated_at = CURRENT_TIMESTAMP
+		WHERE attributes ? $1
+		RETURNING id, name, email, position, salary, manager_id, attributes, created_at, updated_at
+	`, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var emps []employeeEntity.Employee
+	for rows.Next() {
+		var emp employeeEntity.Employee
+		var attrs []byte
+		err := rows.Scan(
+			&emp.ID,
+			&emp.Name,
+			&emp.Email,
+			&emp.Position,
+			&emp.Salary,
+			&emp.ManagerID,
+			&attrs,
+			&emp.CreatedAt,
+			&emp.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(attrs, &emp.Attributes); err != nil {
+			return nil, err
+		}
+		emps = append(emps, emp)
+	}
+
+	return emps, rows.Err()
+}
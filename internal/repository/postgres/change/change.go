@@ -0,0 +1,89 @@
+package change
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+func NewChangeStore(db *sql.DB) *changeStore {
+	return &changeStore{
+		DB: db,
+	}
+}
+
+type changeStore struct {
+	DB *sql.DB
+}
+
+// GetSince returns up to limit changes after afterID, oldest first.
+func (c *changeStore) GetSince(ctx context.Context, afterID int64, limit int) ([]eventEntity.Event, error) {
+	query := `
+		SELECT id, event_type, employee_id, payload, created_at
+		FROM employee_changes
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := c.DB.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []eventEntity.Event
+	for rows.Next() {
+		var evt eventEntity.Event
+		if err := rows.Scan(&evt.ID, &evt.Type, &evt.EmployeeID, (*[]byte)(&evt.Data), &evt.CreatedAt); err != nil {
+			return nil, err
+		}
+		changes = append(changes, evt)
+	}
+
+	return changes, rows.Err()
+}
+
+func (c *changeStore) GetBounds(ctx context.Context) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var oldest, latest int64
+	err := c.DB.QueryRowContext(ctx,
+		`SELECT COALESCE(MIN(id), 0), COALESCE(MAX(id), 0) FROM employee_changes`,
+	).Scan(&oldest, &latest)
+	return oldest, latest, err
+}
+
+func (c *changeStore) GetSnapshot(ctx context.Context) (int64, int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var xmin, xmax int64
+	err := c.DB.QueryRowContext(ctx, `
+		SELECT pg_snapshot_xmin(s)::text::bigint, pg_snapshot_xmax(s)::text::bigint
+		FROM pg_current_snapshot() s
+	`).Scan(&xmin, &xmax)
+	return xmin, xmax, err
+}
+
+// DeleteOlderThan removes changes recorded more than olderThan ago.
+func (c *changeStore) DeleteOlderThan(ctx context.Context, olderThan time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := c.DB.ExecContext(ctx,
+		`DELETE FROM employee_changes WHERE created_at < CURRENT_TIMESTAMP - $1 * interval '1 second'`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -3,10 +3,19 @@ package employee
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
 	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/outbox"
+	"github.com/lib/pq"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
 )
 
 func NewEmployeeStore(db *sql.DB) *employeeStore {
@@ -19,10 +28,116 @@ type employeeStore struct {
 	DB *sql.DB
 }
 
-func (e *employeeStore) GetAll(ctx context.Context) ([]employeeEntity.Employee, error) {
-	query := `SELECT id, name, email, position, salary, created_at FROM employees`
+// attributesColumn scans a JSONB column into an attribute map.
+type attributesColumn struct {
+	dst *map[string]any
+}
+
+func (a attributesColumn) Scan(src any) error {
+	raw, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unexpected attributes type %T", src)
+	}
+	return json.Unmarshal(raw, a.dst)
+}
+
+// column is an employee column with the field it is scanned into.
+type column struct {
+	name   string
+	target func(emp *employeeEntity.Employee) any
+}
+
+// columns are named like the fields of employeeEntity.Fields.
+var columns = []column{
+	{"id", func(emp *employeeEntity.Employee) any { return &emp.ID }},
+	{"name", func(emp *employeeEntity.Employee) any { return &emp.Name }},
+	{"email", func(emp *employeeEntity.Employee) any { return &emp.Email }},
+	{"position", func(emp *employeeEntity.Employee) any { return &emp.Position }},
+	{"salary", func(emp *employeeEntity.Employee) any { return &emp.Salary }},
+	{"manager_id", func(emp *employeeEntity.Employee) any { return &emp.ManagerID }},
+	{"attributes", func(emp *employeeEntity.Employee) any { return attributesColumn{&emp.Attributes} }},
+	{"created_at", func(emp *employeeEntity.Employee) any { return &emp.CreatedAt }},
+	{"updated_at", func(emp *employeeEntity.Employee) any { return &emp.UpdatedAt }},
+}
+
+// selectColumns returns the columns of fields, or all of them for nil. The
+// names are checked against columns, so they are safe to put into a query.
+func selectColumns(fields []string) ([]column, error) {
+	if fields == nil {
+		return columns, nil
+	}
+	selected := make([]column, 0, len(fields))
+	for _, field := range fields {
+		i := slices.IndexFunc(columns, func(c column) bool { return c.name == field })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown employee field %q", field)
+		}
+		selected = append(selected, columns[i])
+	}
+	return selected, nil
+}
+
+func marshalAttributes(attrs map[string]any) ([]byte, error) {
+	if attrs == nil {
+		return []byte(`{}`), nil
+	}
+	return json.Marshal(attrs)
+}
+
+func (e *employeeStore) GetAll(ctx context.Context, filter employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	cols, err := selectColumns(filter.Fields)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.name
+	}
+
+	query := `SELECT ` + strings.Join(names, `, `) + ` FROM employees`
+	var (
+		args  []any
+		where []string
+	)
+
+	if len(filter.Attributes) > 0 {
+		contains, err := json.Marshal(filter.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, contains)
+		where = append(where, fmt.Sprintf(`attributes @> $%d`, len(args)))
+	}
+	if filter.IDs != nil {
+		args = append(args, pq.Array(filter.IDs))
+		where = append(where, fmt.Sprintf(`id = ANY($%d)`, len(args)))
+	}
+	if filter.AfterID > 0 {
+		args = append(args, filter.AfterID)
+		where = append(where, fmt.Sprintf(`id > $%d`, len(args)))
+	}
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, ` AND `)
+	}
+	query += ` ORDER BY id`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
 	
-	rows, err := e.DB.QueryContext(ctx, query)
+	ctx, span := startSpan(ctx, "SELECT", query)
+	employees, err := scanAll(ctx, e.DB, query, args, cols)
+	endSpan(span, err)
+	return employees, err
+}
+
+// queryer is a *sql.DB or *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func scanAll(ctx context.Context, q queryer, query string, args []any, cols []column) ([]employeeEntity.Employee, error) {
+	rows, err := q.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -31,8 +146,11 @@ func (e *employeeStore) GetAll(ctx context.Context) ([]employeeEntity.Employee,
 	var employees []employeeEntity.Employee
 	for rows.Next() {
 		var emp employeeEntity.Employee
-		err := rows.Scan(&emp.ID, &emp.Name, &emp.Email, &emp.Position, &emp.Salary, &emp.CreatedAt)
-		if err != nil {
+		targets := make([]any, len(cols))
+		for i, c := range cols {
+			targets[i] = c.target(&emp)
+		}
+		if err := rows.Scan(targets...); err != nil {
 			return nil, err
 		}
 		employees = append(employees, emp)
@@ -43,7 +161,7 @@ func (e *employeeStore) GetAll(ctx context.Context) ([]employeeEntity.Employee,
 
 func(e *employeeStore) GetById(ctx context.Context, empid int64) (*employeeEntity.Employee, error) {
 	query := `
-		SELECT id, name, email, position, salary, created_at
+		SELECT id, name, email, position, salary, manager_id, attributes, created_at, updated_at
 		FROM employees
 		WHERE id = $1
 	`
@@ -52,6 +170,8 @@ func(e *employeeStore) GetById(ctx context.Context, empid int64) (*employeeEntit
 	defer cancel()
 
 
+	ctx, span := startSpan(ctx, "SELECT", query)
+
 	var emp employeeEntity.Employee
 	err := e.DB.QueryRowContext(
 		ctx,
@@ -63,16 +183,18 @@ func(e *employeeStore) GetById(ctx context.Context, empid int64) (*employeeEntit
 		&emp.Email,
 		&emp.Position,
 		&emp.Salary,
+		&emp.ManagerID,
+		attributesColumn{&emp.Attributes},
 		&emp.CreatedAt,
+		&emp.UpdatedAt,
 	)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = repository.ErrNotFound
+	}
+	endSpan(span, err)
 
 	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return nil, repository.ErrNotFound
-		default:
-			return nil, err
-		}
+		return nil, err
 	}
 
 	return &emp, nil
@@ -81,21 +203,37 @@ func(e *employeeStore) GetById(ctx context.Context, empid int64) (*employeeEntit
 
 func(e *employeeStore) Create(ctx context.Context, emp *employeeEntity.Employee) error {
 	query := `
-		INSERT INTO employees (name, email, position, salary)
-		VALUES ($1, $2, $3, $4) RETURNING id, created_at
+		INSERT INTO employees (name, email, position, salary, manager_id, attributes)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at
 	`
 
+	attrs, err := marshalAttributes(emp.Attributes)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
 	defer cancel()
 
-	err := e.DB.QueryRowContext(
-		ctx,
-		query,
-		emp.Name,
-		emp.Email,
-		emp.Position,
-		emp.Salary,
-	).Scan(&emp.ID, &emp.CreatedAt)
+	err = repository.WithTx(e.DB, ctx, func(tx *sql.Tx) error {
+		ctx, span := startSpan(ctx, "INSERT", query)
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			emp.Name,
+			emp.Email,
+			emp.Position,
+			emp.Salary,
+			emp.ManagerID,
+			attrs,
+		).Scan(&emp.ID, &emp.CreatedAt, &emp.UpdatedAt)
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
+
+		return outbox.Enqueue(ctx, tx, eventEntity.EmployeeCreated, emp.ID, emp)
+	})
 
 	if err != nil {
 		switch {
@@ -105,6 +243,8 @@ func(e *employeeStore) Create(ctx context.Context, emp *employeeEntity.Employee)
 			return repository.ErrNullEmail
 		case err.Error() == `pq: salary canot be null or neagative`:
 			return repository.ErrNullOrNegSalary
+		case err.Error() == `pq: insert or update on table "employees" violates foreign key constraint "employees_manager_id_fkey"`:
+			return repository.ErrManagerNotFound
 		default:
 			return err
 		}
@@ -113,27 +253,132 @@ func(e *employeeStore) Create(ctx context.Context, emp *employeeEntity.Employee)
 	return nil
 }
 
+// CreateMany bulk loads employees with COPY in a single transaction. A zero
+// CreatedAt is stored as the current time and every employee starts out last
+// updated when created. COPY returns nothing, so the rows are read back by
+// email to fill in the IDs and enqueue an employee.created event for each.
+func (e *employeeStore) CreateMany(ctx context.Context, emps []employeeEntity.Employee) error {
+	now := time.Now()
+
+	ctx, span := startSpan(ctx, "COPY", "COPY employees (name, email, position, salary, manager_id, attributes, created_at, updated_at) FROM STDIN")
+	span.SetAttributes(semconv.DBOperationBatchSize(len(emps)))
+	err := repository.WithTx(e.DB, ctx, func(tx *sql.Tx) error {
+		stmt, err := tx.PrepareContext(ctx, pq.CopyIn("employees", "name", "email", "position", "salary", "manager_id", "attributes", "created_at", "updated_at"))
+		if err != nil {
+			return err
+		}
+		defer stmt.Close()
+
+		for i := range emps {
+			attrs, err := marshalAttributes(emps[i].Attributes)
+			if err != nil {
+				return err
+			}
+
+			createdAt := emps[i].CreatedAt
+			if createdAt.IsZero() {
+				createdAt = now
+			}
+
+			_, err = stmt.ExecContext(
+				ctx,
+				emps[i].Name,
+				emps[i].Email,
+				emps[i].Position,
+				emps[i].Salary,
+				emps[i].ManagerID,
+				string(attrs),
+				createdAt,
+				createdAt,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := stmt.ExecContext(ctx); err != nil {
+			switch {
+			case err.Error() == `pq: duplicate key value violates unique constraint "employees_email_key"`:
+				return repository.ErrUniqueViolation
+			default:
+				return err
+			}
+		}
+
+		emails := make([]string, len(emps))
+		for i := range emps {
+			emails[i] = emps[i].Email
+		}
+		created, err := scanAll(ctx, tx, `
+			SELECT id, name, email, position, salary, manager_id, attributes, created_at, updated_at
+			FROM employees
+			WHERE email = ANY($1)
+		`, []any{pq.Array(emails)}, columns)
+		if err != nil {
+			return err
+		}
+
+		ids := make(map[string]int64, len(created))
+		for i := range created {
+			ids[created[i].Email] = created[i].ID
+			if err := outbox.Enqueue(ctx, tx, eventEntity.EmployeeCreated, created[i].ID, created[i]); err != nil {
+				return err
+			}
+		}
+		for i := range emps {
+			emps[i].ID = ids[emps[i].Email]
+		}
+		return nil
+	})
+	endSpan(span, err)
+	return err
+}
+
 func(e *employeeStore) Update(ctx context.Context, emp *employeeEntity.Employee) error {
 	query := `
 		UPDATE employees SET
 		name = $1,
 		email = $2,
 		position = $3,
-		salary = $4
-		WHERE id = $5
+		salary = $4,
+		manager_id = $5,
+		attributes = $6,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING created_at, updated_at
 	`
 
+	attrs, err := marshalAttributes(emp.Attributes)
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
 	defer cancel()
 
-	_, err := e.DB.ExecContext(
-		ctx, 
-		query, 
-		emp.Name,
-		emp.Email,
-		emp.Position,
-		emp.Salary,
-		emp.ID);
+	err = repository.WithTx(e.DB, ctx, func(tx *sql.Tx) error {
+		ctx, span := startSpan(ctx, "UPDATE", query)
+		err := tx.QueryRowContext(
+			ctx, 
+			query, 
+			emp.Name,
+			emp.Email,
+			emp.Position,
+			emp.Salary,
+			emp.ManagerID,
+			attrs,
+			emp.ID,
+		).Scan(&emp.CreatedAt, &emp.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = repository.ErrNotFound
+		}
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
+
+		return outbox.Enqueue(ctx, tx, eventEntity.EmployeeUpdated, emp.ID, emp)
+	})
 
 	if err != nil {
 		switch {
@@ -141,6 +386,8 @@ func(e *employeeStore) Update(ctx context.Context, emp *employeeEntity.Employee)
 				return repository.ErrUniqueViolation
 			case err.Error() == `pq: salary canot be null or neagative`:
 				return repository.ErrNullOrNegSalary
+			case err.Error() == `pq: insert or update on table "employees" violates foreign key constraint "employees_manager_id_fkey"`:
+				return repository.ErrManagerNotFound
 			default:
 				return err
 		}
@@ -149,26 +396,59 @@ func(e *employeeStore) Update(ctx context.Context, emp *employeeEntity.Employee)
 	return nil
 }
 
+// Delete removes the employee and clears manager_id of their reports in the
+// same transaction, enqueueing employee.updated for every report besides the
+// employee.deleted event. The foreign key would clear it as well, but without
+// events.
 func(e *employeeStore) Delete(ctx context.Context, empId int64) error {
 	query := `
 		DELETE FROM employees WHERE id = $1
+		RETURNING id, name, email, position, salary, manager_id, attributes, created_at, updated_at
+	`
+	reportsQuery := `
+		UPDATE employees SET manager_id = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE manager_id = $1
+		RETURNING id, name, email, position, salary, manager_id, attributes, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
 	defer cancel()
 
-	result, err := e.DB.ExecContext(ctx, query, empId)
-	if err != nil{
-		return err
-	}
+	return repository.WithTx(e.DB, ctx, func(tx *sql.Tx) error {
+		reportsCtx, span := startSpan(ctx, "UPDATE", reportsQuery)
+		reports, err := scanAll(reportsCtx, tx, reportsQuery, []any{empId}, columns)
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
 
-	isAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	if isAffected == 0 {
-		return repository.ErrNotFound
-	}
+		ctx, span := startSpan(ctx, "DELETE", query)
+		// The deleted row becomes the payload of the event.
+		var emp employeeEntity.Employee
+		err = tx.QueryRowContext(ctx, query, empId).Scan(
+			&emp.ID,
+			&emp.Name,
+			&emp.Email,
+			&emp.Position,
+			&emp.Salary,
+			&emp.ManagerID,
+			attributesColumn{&emp.Attributes},
+			&emp.CreatedAt,
+			&emp.UpdatedAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = repository.ErrNotFound
+		}
+		endSpan(span, err)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		for i := range reports {
+			if err := outbox.Enqueue(ctx, tx, eventEntity.EmployeeUpdated, reports[i].ID, reports[i]); err != nil {
+				return err
+			}
+		}
+		return outbox.Enqueue(ctx, tx, eventEntity.EmployeeDeleted, emp.ID, emp)
+	})
 }
\ No newline at end of file
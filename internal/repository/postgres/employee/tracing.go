@@ -0,0 +1,38 @@
+package employee
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.43.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres/employee")
+
+// startSpan opens a client span for one SQL statement against employees.
+func startSpan(ctx context.Context, operation, query string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, operation+" employees",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemNamePostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName("employees"),
+			semconv.DBQueryText(strings.Join(strings.Fields(query), " ")),
+		),
+	)
+}
+
+// endSpan records err, unless it only means the row does not exist, and
+// ends the span.
+func endSpan(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
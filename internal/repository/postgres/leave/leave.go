@@ -0,0 +1,498 @@
+package leave
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	leaveEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/leaves"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+func NewLeaveStore(db *sql.DB) *leaveStore {
+	return &leaveStore{
+		DB: db,
+	}
+}
+
+type leaveStore struct {
+	DB *sql.DB
+}
+
+const requestColumns = `id, employee_id, leave_type_id, start_date, end_date, days, reason, status, decided_by, decided_at, created_at`
+
+func scanRequest(row interface{ Scan(...any) error }, req *leaveEntity.LeaveRequest) error {
+	return row.Scan(
+		&req.ID,
+		&req.EmployeeID,
+		&req.LeaveTypeID,
+		&req.StartDate,
+		&req.EndDate,
+		&req.Days,
+		&req.Reason,
+		&req.Status,
+		&req.DecidedBy,
+		&req.DecidedAt,
+		&req.CreatedAt,
+	)
+}
+
+func (l *leaveStore) GetTypes(ctx context.Context) ([]leaveEntity.LeaveType, error) {
+	query := `
+		SELECT id, name, annual_days, accrual_method, max_carry_over, created_at
+		FROM leave_types
+		ORDER BY id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := l.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []leaveEntity.LeaveType
+	for rows.Next() {
+		var lt leaveEntity.LeaveType
+		err := rows.Scan(&lt.ID, &lt.Name, &lt.AnnualDays, &lt.AccrualMethod, &lt.MaxCarryOver, &lt.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, lt)
+	}
+
+	return types, rows.Err()
+}
+
+func (l *leaveStore) CreateType(ctx context.Context, lt *leaveEntity.LeaveType) error {
+	query := `
+		INSERT INTO leave_types (name, annual_days, accrual_method, max_carry_over)
+		VALUES ($1, $2, $3, $4) RETURNING id, created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	err := l.DB.QueryRowContext(
+		ctx,
+		query,
+		lt.Name,
+		lt.AnnualDays,
+		lt.AccrualMethod,
+		lt.MaxCarryOver,
+	).Scan(&lt.ID, &lt.CreatedAt)
+
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "leave_types_name_key"`:
+			return repository.ErrLeaveTypeExists
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l *leaveStore) GetRequestById(ctx context.Context, id int64) (*leaveEntity.LeaveRequest, error) {
+	query := `SELECT ` + requestColumns + ` FROM leave_requests WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var req leaveEntity.LeaveRequest
+	if err := scanRequest(l.DB.QueryRowContext(ctx, query, id), &req); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &req, nil
+}
+
+func (l *leaveStore) GetRequestsByEmployee(ctx context.Context, employeeID int64) ([]leaveEntity.LeaveRequest, error) {
+	query := `
+		SELECT ` + requestColumns + `
+		FROM leave_requests
+		WHERE employee_id = $1
+		ORDER BY start_date DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := l.DB.QueryContext(ctx, query, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []leaveEntity.LeaveRequest{}
+	for rows.Next() {
+		var req leaveEntity.LeaveRequest
+		if err := scanRequest(rows, &req); err != nil {
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// CreateRequest locks the employee row so concurrent submissions for the same
+// employee are serialised, then checks for overlaps and remaining balance
+// before inserting the request as pending.
+func (l *leaveStore) CreateRequest(ctx context.Context, req *leaveEntity.LeaveRequest) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	return repository.WithTx(l.DB, ctx, func(tx *sql.Tx) error {
+		var locked int64
+		err := tx.QueryRowContext(ctx, `SELECT id FROM employees WHERE id = $1 FOR UPDATE`, req.EmployeeID).Scan(&locked)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return repository.ErrNotFound
+			}
+			return err
+		}
+
+		lt, err := getType(ctx, tx, req.LeaveTypeID)
+		if err != nil {
+			return err
+		}
+
+		var overlaps bool
+		err = tx.QueryRowContext(ctx, `
+			SELECT EXISTS (
+				SELECT 1 FROM leave_requests
+				WHERE employee_id = $1
+				AND status IN ('pending', 'approved')
+				AND start_date <= $3
+				AND end_date >= $2
+			)
+		`, req.EmployeeID, req.StartDate, req.EndDate).Scan(&overlaps)
+		if err != nil {
+			return err
+		}
+		if overlaps {
+			return repository.ErrLeaveOverlap
+		}
+
+		balance, err := ensureBalance(ctx, tx, req.EmployeeID, lt, req.StartDate.Year())
+		if err != nil {
+			return err
+		}
+		if entitled(lt, balance, req.StartDate)-balance.UsedDays-balance.PendingDays < req.Days {
+			return repository.ErrInsufficientBalance
+		}
+
+		query := `
+			INSERT INTO leave_requests (employee_id, leave_type_id, start_date, end_date, days, reason)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, status, created_at
+		`
+		return tx.QueryRowContext(
+			ctx,
+			query,
+			req.EmployeeID,
+			req.LeaveTypeID,
+			req.StartDate,
+			req.EndDate,
+			req.Days,
+			req.Reason,
+		).Scan(&req.ID, &req.Status, &req.CreatedAt)
+	})
+}
+
+// Approve marks a pending request approved and deducts its days from the
+// balance of the year it starts in, all inside one transaction.
+func (l *leaveStore) Approve(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var req leaveEntity.LeaveRequest
+	err := repository.WithTx(l.DB, ctx, func(tx *sql.Tx) error {
+		if err := getPendingForDecision(ctx, tx, requestID, approverID, &req); err != nil {
+			return err
+		}
+
+		lt, err := getType(ctx, tx, req.LeaveTypeID)
+		if err != nil {
+			return err
+		}
+
+		balance, err := ensureBalance(ctx, tx, req.EmployeeID, lt, req.StartDate.Year())
+		if err != nil {
+			return err
+		}
+		// Other pending requests are only reserved, approval checks what is
+		// actually left after approved leave.
+		if entitled(lt, balance, req.StartDate)-balance.UsedDays < req.Days {
+			return repository.ErrInsufficientBalance
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			UPDATE leave_balances SET used_days = used_days + $4
+			WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+		`, req.EmployeeID, req.LeaveTypeID, balance.Year, req.Days)
+		if err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			UPDATE leave_requests SET status = $2, decided_by = $3, decided_at = NOW()
+			WHERE id = $1
+			RETURNING status, decided_by, decided_at
+		`, req.ID, leaveEntity.StatusApproved, approverID).Scan(&req.Status, &req.DecidedBy, &req.DecidedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+func (l *leaveStore) Reject(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var req leaveEntity.LeaveRequest
+	err := repository.WithTx(l.DB, ctx, func(tx *sql.Tx) error {
+		if err := getPendingForDecision(ctx, tx, requestID, approverID, &req); err != nil {
+			return err
+		}
+
+		return tx.QueryRowContext(ctx, `
+			UPDATE leave_requests SET status = $2, decided_by = $3, decided_at = NOW()
+			WHERE id = $1
+			RETURNING status, decided_by, decided_at
+		`, req.ID, leaveEntity.StatusRejected, approverID).Scan(&req.Status, &req.DecidedBy, &req.DecidedAt)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &req, nil
+}
+
+// GetBalances returns one balance per leave type for the given year, accrued
+// up to today. It only reads: balance rows are written when leave is
+// requested or approved.
+func (l *leaveStore) GetBalances(ctx context.Context, employeeID int64, year int) ([]leaveEntity.LeaveBalance, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var exists bool
+	err := l.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, employeeID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, repository.ErrNotFound
+	}
+
+	types, err := l.GetTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	balances := []leaveEntity.LeaveBalance{}
+	for i := range types {
+		balance, err := readBalance(ctx, l.DB, employeeID, &types[i], year, now)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, *balance)
+	}
+
+	return balances, nil
+}
+
+// getPendingForDecision loads the request locked for update and checks that
+// approverID manages the employee who filed it. The manager is read FOR
+// SHARE, so it cannot be reassigned before the decision commits.
+func getPendingForDecision(ctx context.Context, tx *sql.Tx, requestID int64, approverID int64, req *leaveEntity.LeaveRequest) error {
+	query := `SELECT ` + requestColumns + ` FROM leave_requests WHERE id = $1 FOR UPDATE`
+	if err := scanRequest(tx.QueryRowContext(ctx, query, requestID), req); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+
+	var managerID sql.NullInt64
+	err := tx.QueryRowContext(ctx, `SELECT manager_id FROM employees WHERE id = $1 FOR SHARE`, req.EmployeeID).Scan(&managerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+	if !managerID.Valid || managerID.Int64 != approverID {
+		return repository.ErrNotManager
+	}
+
+	if req.Status != leaveEntity.StatusPending {
+		return repository.ErrLeaveNotPending
+	}
+	return nil
+}
+
+func getType(ctx context.Context, tx *sql.Tx, id int64) (*leaveEntity.LeaveType, error) {
+	query := `
+		SELECT id, name, annual_days, accrual_method, max_carry_over, created_at
+		FROM leave_types
+		WHERE id = $1
+	`
+
+	var lt leaveEntity.LeaveType
+	err := tx.QueryRowContext(ctx, query, id).Scan(
+		&lt.ID,
+		&lt.Name,
+		&lt.AnnualDays,
+		&lt.AccrualMethod,
+		&lt.MaxCarryOver,
+		&lt.CreatedAt,
+	)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrLeaveTypeNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &lt, nil
+}
+
+// queryer is the part of *sql.DB and *sql.Tx the balance queries use.
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// ensureBalance creates or tops up the balance row of year, accrued up to
+// today, and returns it locked for the rest of the transaction. Accrual only
+// ever grows the balance, and the first row of a year carries over what was
+// left of the previous one up to the type's limit.
+func ensureBalance(ctx context.Context, tx *sql.Tx, employeeID int64, lt *leaveEntity.LeaveType, year int) (*leaveEntity.LeaveBalance, error) {
+	carriedOver, err := carryOver(ctx, tx, employeeID, lt, year)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := leaveEntity.LeaveBalance{
+		EmployeeID:    employeeID,
+		LeaveTypeID:   lt.ID,
+		LeaveTypeName: lt.Name,
+		Year:          year,
+	}
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO leave_balances (employee_id, leave_type_id, year, accrued_days, carried_over_days)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (employee_id, leave_type_id, year)
+		DO UPDATE SET accrued_days = GREATEST(leave_balances.accrued_days, EXCLUDED.accrued_days)
+		RETURNING accrued_days, carried_over_days, used_days
+	`, employeeID, lt.ID, year, accruedDays(lt, year, time.Now().UTC()), carriedOver).Scan(
+		&balance.AccruedDays,
+		&balance.CarriedOverDays,
+		&balance.UsedDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if balance.PendingDays, err = pendingDays(ctx, tx, employeeID, lt, year); err != nil {
+		return nil, err
+	}
+
+	balance.AvailableDays = balance.AccruedDays + balance.CarriedOverDays - balance.UsedDays - balance.PendingDays
+	return &balance, nil
+}
+
+// readBalance is ensureBalance without writing: a missing row is computed
+// the way ensureBalance would create it, and a stored one is topped up to
+// what has accrued by now.
+func readBalance(ctx context.Context, q queryer, employeeID int64, lt *leaveEntity.LeaveType, year int, now time.Time) (*leaveEntity.LeaveBalance, error) {
+	balance := leaveEntity.LeaveBalance{
+		EmployeeID:    employeeID,
+		LeaveTypeID:   lt.ID,
+		LeaveTypeName: lt.Name,
+		Year:          year,
+	}
+	err := q.QueryRowContext(ctx, `
+		SELECT accrued_days, carried_over_days, used_days
+		FROM leave_balances
+		WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+	`, employeeID, lt.ID, year).Scan(&balance.AccruedDays, &balance.CarriedOverDays, &balance.UsedDays)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		if balance.CarriedOverDays, err = carryOver(ctx, q, employeeID, lt, year); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	}
+	balance.AccruedDays = math.Max(balance.AccruedDays, accruedDays(lt, year, now))
+
+	if balance.PendingDays, err = pendingDays(ctx, q, employeeID, lt, year); err != nil {
+		return nil, err
+	}
+
+	balance.AvailableDays = balance.AccruedDays + balance.CarriedOverDays - balance.UsedDays - balance.PendingDays
+	return &balance, nil
+}
+
+// carryOver is what a new balance row of year takes over from the year
+// before.
+func carryOver(ctx context.Context, q queryer, employeeID int64, lt *leaveEntity.LeaveType, year int) (float64, error) {
+	var remaining float64
+	err := q.QueryRowContext(ctx, `
+		SELECT accrued_days + carried_over_days - used_days
+		FROM leave_balances
+		WHERE employee_id = $1 AND leave_type_id = $2 AND year = $3
+	`, employeeID, lt.ID, year-1).Scan(&remaining)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return 0, err
+	}
+	return lt.CarryOver(remaining), nil
+}
+
+func pendingDays(ctx context.Context, q queryer, employeeID int64, lt *leaveEntity.LeaveType, year int) (float64, error) {
+	var days float64
+	err := q.QueryRowContext(ctx, `
+		SELECT COALESCE(SUM(days), 0)
+		FROM leave_requests
+		WHERE employee_id = $1
+		AND leave_type_id = $2
+		AND status = 'pending'
+		AND EXTRACT(YEAR FROM start_date) = $3
+	`, employeeID, lt.ID, year).Scan(&days)
+	return days, err
+}
+
+// accruedDays is what lt has earned in year by now: the whole year for past
+// years and nothing for years that have not started.
+func accruedDays(lt *leaveEntity.LeaveType, year int, now time.Time) float64 {
+	switch {
+	case year < now.Year():
+		return lt.AccruedDays(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+	case year > now.Year():
+		return 0
+	}
+	return lt.AccruedDays(now)
+}
+
+// entitled is what the balance grants to leave starting on start: the
+// allowance accrued by that day, which for future leave is more than the
+// balance row holds yet, plus what was carried over.
+func entitled(lt *leaveEntity.LeaveType, balance *leaveEntity.LeaveBalance, start time.Time) float64 {
+	return lt.AccruedDays(start) + balance.CarriedOverDays
+}
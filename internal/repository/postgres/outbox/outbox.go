@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+// Enqueue records an event inside tx, so it is only ever published when the
+// change it describes commits.
+func Enqueue(ctx context.Context, tx *sql.Tx, eventType string, employeeID int64, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox (event_type, employee_id, payload) VALUES ($1, $2, $3)`,
+		eventType, employeeID, payload,
+	)
+	return err
+}
+
+func NewOutboxStore(db *sql.DB) *outboxStore {
+	return &outboxStore{
+		DB: db,
+	}
+}
+
+type outboxStore struct {
+	DB *sql.DB
+}
+
+// GetPending returns due events, oldest first, but only the oldest
+// unpublished event of each employee: a later event waits until the ones
+// before it are out, which keeps delivery ordered per employee.
+func (o *outboxStore) GetPending(ctx context.Context, limit int) ([]eventEntity.Event, error) {
+	query := `
+		SELECT o.id, o.event_type, o.employee_id, o.payload, o.created_at, o.attempts
+		FROM outbox o
+		WHERE o.published_at IS NULL
+		AND o.next_attempt_at <= CURRENT_TIMESTAMP
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox p
+			WHERE p.employee_id = o.employee_id
+			AND p.published_at IS NULL
+			AND p.id < o.id
+		)
+		ORDER BY o.id
+		LIMIT $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := o.DB.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []eventEntity.Event
+	for rows.Next() {
+		var evt eventEntity.Event
+		if err := rows.Scan(&evt.ID, &evt.Type, &evt.EmployeeID, &evt.Data, &evt.CreatedAt, &evt.Attempts); err != nil {
+			return nil, err
+		}
+		events = append(events, evt)
+	}
+
+	return events, rows.Err()
+}
+
+func (o *outboxStore) MarkPublished(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := o.DB.ExecContext(ctx,
+		`UPDATE outbox SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL WHERE id = $1`,
+		id,
+	)
+	return err
+}
+
+// MarkFailed records the failure and delays the next attempt by retryIn.
+// Times are computed by Postgres, like the CURRENT_TIMESTAMP defaults.
+func (o *outboxStore) MarkFailed(ctx context.Context, id int64, cause error, retryIn time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := o.DB.ExecContext(ctx,
+		`UPDATE outbox SET attempts = attempts + 1, last_error = $2, next_attempt_at = CURRENT_TIMESTAMP + $3 * interval '1 second' WHERE id = $1`,
+		id, cause.Error(), retryIn.Seconds(),
+	)
+	return err
+}
+
+// DeletePublished removes events published more than olderThan ago.
+func (o *outboxStore) DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error) {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	result, err := o.DB.ExecContext(ctx, `DELETE FROM outbox WHERE published_at < CURRENT_TIMESTAMP - $1 * interval '1 second'`, olderThan.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
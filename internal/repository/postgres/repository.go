@@ -7,7 +7,13 @@ import (
 	"database/sql"
 	"errors"
 	"time"
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
 	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	leaveEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/leaves"
+	timeEntryEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/timeentries"
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
 )
 
 var(
@@ -16,20 +22,114 @@ var(
 	ErrNullEmail = errors.New("email cannot be null")
 	ErrUniqueViolation = errors.New("an employee wiht this memail already exists")
 	ErrNullOrNegSalary = errors.New("salary cannot be null or negative")
+	ErrManagerNotFound = errors.New("manager does not exist")
+	ErrLeaveTypeExists = errors.New("a leave type with this name already exists")
+	ErrLeaveTypeNotFound = errors.New("leave type does not exist")
+	ErrLeaveOverlap = errors.New("leave request overlaps an existing request")
+	ErrInsufficientBalance = errors.New("insufficient leave balance")
+	ErrLeaveNotPending = errors.New("leave request has already been decided")
+	ErrNotManager = errors.New("only the employee's manager can decide this request")
+	ErrTimeEntryOverlap = errors.New("time entry overlaps an existing entry")
+	ErrTimeEntryOpen = errors.New("employee is already clocked in")
+	ErrNoOpenTimeEntry = errors.New("employee is not clocked in")
+	ErrTimeEntryLocked = errors.New("time entries before the lock date cannot be changed")
+	ErrAttributeExists = errors.New("an attribute with this name already exists")
+	ErrAttachmentTooLarge = errors.New("attachment exceeds the size limit")
+	ErrUnsupportedMediaType = errors.New("attachment type is not allowed")
+	ErrChecksumMismatch = errors.New("attachment checksum does not match")
 )
 
 type Repository struct {
 	Employee EmployeeRepository
+	Leave LeaveRepository
+	TimeEntry TimeEntryRepository
+	Attribute AttributeRepository
+	Attachment AttachmentRepository
+	Outbox OutboxRepository
+	Webhook WebhookRepository
+	Change ChangeRepository
 }
 
 type EmployeeRepository interface {
-	GetAll(context.Context) ([]employeeEntity.Employee, error)
+	GetAll(context.Context, employeeEntity.ListFilter) ([]employeeEntity.Employee, error)
 	GetById(context.Context, int64) (*employeeEntity.Employee, error)
 	Create(context.Context, *employeeEntity.Employee) error
 	Update(context.Context, *employeeEntity.Employee) error
 	Delete(context.Context, int64) error
 }
 
+// OutboxRepository reads and settles employee change events. Events are
+// written with outbox.Enqueue inside the transaction of the change.
+type OutboxRepository interface {
+	GetPending(ctx context.Context, limit int) ([]eventEntity.Event, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, cause error, retryIn time.Duration) error
+	DeletePublished(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// ChangeRepository reads the employee change log, which a trigger on the
+// employees table writes.
+type ChangeRepository interface {
+	GetSince(ctx context.Context, afterID int64, limit int) ([]eventEntity.Event, error)
+	// GetBounds returns the oldest and newest id still in the log, zeros
+	// when it is empty.
+	GetBounds(ctx context.Context) (oldest int64, latest int64, err error)
+	// GetSnapshot returns the oldest transaction still running and the
+	// next one to start, see pg_current_snapshot.
+	GetSnapshot(ctx context.Context) (xmin int64, xmax int64, err error)
+	DeleteOlderThan(ctx context.Context, olderThan time.Duration) (int64, error)
+}
+
+// WebhookRepository manages webhook subscriptions and their delivery log.
+// Deliveries are created by FanOut from outbox events, claimed by the
+// deliverer with ClaimDue and settled with RecordAttempt.
+type WebhookRepository interface {
+	GetAll(context.Context) ([]webhookEntity.Subscription, error)
+	GetById(context.Context, int64) (*webhookEntity.Subscription, error)
+	Create(context.Context, *webhookEntity.Subscription) error
+	Update(context.Context, *webhookEntity.Subscription) error
+	Delete(context.Context, int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhookEntity.Delivery, error)
+	CreateDelivery(context.Context, *webhookEntity.Delivery) error
+	FanOut(context.Context, eventEntity.Event) (int64, error)
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]webhookEntity.Job, error)
+	RecordAttempt(context.Context, webhookEntity.Attempt) error
+	Redeliver(ctx context.Context, subscriptionID int64, id int64) (*webhookEntity.Delivery, error)
+}
+
+type LeaveRepository interface {
+	GetTypes(context.Context) ([]leaveEntity.LeaveType, error)
+	CreateType(context.Context, *leaveEntity.LeaveType) error
+	GetRequestById(context.Context, int64) (*leaveEntity.LeaveRequest, error)
+	GetRequestsByEmployee(context.Context, int64) ([]leaveEntity.LeaveRequest, error)
+	CreateRequest(context.Context, *leaveEntity.LeaveRequest) error
+	Approve(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error)
+	Reject(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error)
+	GetBalances(ctx context.Context, employeeID int64, year int) ([]leaveEntity.LeaveBalance, error)
+}
+
+type AttributeRepository interface {
+	GetDefinitions(context.Context) ([]attributeEntity.Definition, error)
+	CreateDefinition(context.Context, *attributeEntity.Definition) error
+	DeleteDefinition(context.Context, int64) error
+}
+
+type AttachmentRepository interface {
+	GetById(ctx context.Context, employeeID int64, id int64) (*attachmentEntity.Attachment, error)
+	GetByEmployee(context.Context, int64) ([]attachmentEntity.Attachment, error)
+	Create(context.Context, *attachmentEntity.Attachment) error
+	Delete(ctx context.Context, employeeID int64, id int64) error
+}
+
+type TimeEntryRepository interface {
+	GetById(context.Context, int64) (*timeEntryEntity.TimeEntry, error)
+	GetByEmployee(ctx context.Context, employeeID int64, from time.Time, to time.Time) ([]timeEntryEntity.TimeEntry, error)
+	Start(context.Context, *timeEntryEntity.TimeEntry) error
+	GetOpen(ctx context.Context, employeeID int64) (*timeEntryEntity.TimeEntry, error)
+	Stop(ctx context.Context, employeeID int64, at time.Time) (*timeEntryEntity.TimeEntry, error)
+	Update(context.Context, *timeEntryEntity.TimeEntry) error
+}
+
 func WithTx(db *sql.DB, ctx context.Context, fn func(*sql.Tx) error) error {
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -0,0 +1,229 @@
+package timeentry
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	timeEntryEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/timeentries"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+func NewTimeEntryStore(db *sql.DB) *timeEntryStore {
+	return &timeEntryStore{
+		DB: db,
+	}
+}
+
+type timeEntryStore struct {
+	DB *sql.DB
+}
+
+const entryColumns = `id, employee_id, clock_in, clock_out, note, created_at, updated_at`
+
+func scanEntry(row interface{ Scan(...any) error }, entry *timeEntryEntity.TimeEntry) error {
+	return row.Scan(
+		&entry.ID,
+		&entry.EmployeeID,
+		&entry.ClockIn,
+		&entry.ClockOut,
+		&entry.Note,
+		&entry.CreatedAt,
+		&entry.UpdatedAt,
+	)
+}
+
+func (t *timeEntryStore) GetById(ctx context.Context, id int64) (*timeEntryEntity.TimeEntry, error) {
+	query := `SELECT ` + entryColumns + ` FROM time_entries WHERE id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var entry timeEntryEntity.TimeEntry
+	if err := scanEntry(t.DB.QueryRowContext(ctx, query, id), &entry); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+// GetByEmployee returns the entries clocked in within [from, to).
+func (t *timeEntryStore) GetByEmployee(ctx context.Context, employeeID int64, from time.Time, to time.Time) ([]timeEntryEntity.TimeEntry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE employee_id = $1 AND clock_in >= $2 AND clock_in < $3
+		ORDER BY clock_in
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := t.DB.QueryContext(ctx, query, employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []timeEntryEntity.TimeEntry{}
+	for rows.Next() {
+		var entry timeEntryEntity.TimeEntry
+		if err := scanEntry(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+func (t *timeEntryStore) Start(ctx context.Context, entry *timeEntryEntity.TimeEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	return repository.WithTx(t.DB, ctx, func(tx *sql.Tx) error {
+		if err := lockEmployee(ctx, tx, entry.EmployeeID); err != nil {
+			return err
+		}
+
+		var open bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM time_entries WHERE employee_id = $1 AND clock_out IS NULL)
+		`, entry.EmployeeID).Scan(&open)
+		if err != nil {
+			return err
+		}
+		if open {
+			return repository.ErrTimeEntryOpen
+		}
+
+		if err := checkOverlap(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		query := `
+			INSERT INTO time_entries (employee_id, clock_in, note)
+			VALUES ($1, $2, $3) RETURNING id, created_at, updated_at
+		`
+		return tx.QueryRowContext(
+			ctx,
+			query,
+			entry.EmployeeID,
+			entry.ClockIn,
+			entry.Note,
+		).Scan(&entry.ID, &entry.CreatedAt, &entry.UpdatedAt)
+	})
+}
+
+// GetOpen returns the running entry of the employee.
+func (t *timeEntryStore) GetOpen(ctx context.Context, employeeID int64) (*timeEntryEntity.TimeEntry, error) {
+	query := `SELECT ` + entryColumns + ` FROM time_entries WHERE employee_id = $1 AND clock_out IS NULL`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var entry timeEntryEntity.TimeEntry
+	if err := scanEntry(t.DB.QueryRowContext(ctx, query, employeeID), &entry); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNoOpenTimeEntry
+		default:
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+func (t *timeEntryStore) Stop(ctx context.Context, employeeID int64, at time.Time) (*timeEntryEntity.TimeEntry, error) {
+	query := `
+		UPDATE time_entries SET clock_out = $2, updated_at = NOW()
+		WHERE employee_id = $1 AND clock_out IS NULL
+		RETURNING ` + entryColumns
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var entry timeEntryEntity.TimeEntry
+	if err := scanEntry(t.DB.QueryRowContext(ctx, query, employeeID, at), &entry); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, repository.ErrNoOpenTimeEntry
+		default:
+			return nil, err
+		}
+	}
+
+	return &entry, nil
+}
+
+func (t *timeEntryStore) Update(ctx context.Context, entry *timeEntryEntity.TimeEntry) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	return repository.WithTx(t.DB, ctx, func(tx *sql.Tx) error {
+		if err := lockEmployee(ctx, tx, entry.EmployeeID); err != nil {
+			return err
+		}
+
+		if err := checkOverlap(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		query := `
+			UPDATE time_entries SET clock_in = $2, clock_out = $3, note = $4, updated_at = NOW()
+			WHERE id = $1
+			RETURNING created_at, updated_at
+		`
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			entry.ID,
+			entry.ClockIn,
+			entry.ClockOut,
+			entry.Note,
+		).Scan(&entry.CreatedAt, &entry.UpdatedAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	})
+}
+
+// lockEmployee serialises time entry writes per employee so the overlap
+// check cannot race with a concurrent insert.
+func lockEmployee(ctx context.Context, tx *sql.Tx, employeeID int64) error {
+	var id int64
+	err := tx.QueryRowContext(ctx, `SELECT id FROM employees WHERE id = $1 FOR UPDATE`, employeeID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return repository.ErrNotFound
+	}
+	return err
+}
+
+// checkOverlap treats open entries as running until the end of time.
+func checkOverlap(ctx context.Context, tx *sql.Tx, entry *timeEntryEntity.TimeEntry) error {
+	var overlaps bool
+	err := tx.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM time_entries
+			WHERE employee_id = $1
+			AND id <> $2
+			AND clock_in < COALESCE($4, 'infinity'::timestamptz)
+			AND COALESCE(clock_out, 'infinity'::timestamptz) > $3
+		)
+	`, entry.EmployeeID, entry.ID, entry.ClockIn, entry.ClockOut).Scan(&overlaps)
+	if err != nil {
+		return err
+	}
+	if overlaps {
+		return repository.ErrTimeEntryOverlap
+	}
+	return nil
+}
@@ -0,0 +1,347 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/lib/pq"
+)
+
+const deliveryColumns = `
+	d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+	d.next_attempt_at, d.last_attempt_at, d.last_status_code, d.last_error, d.delivered_at, d.created_at`
+
+func NewWebhookStore(db *sql.DB) *webhookStore {
+	return &webhookStore{
+		DB: db,
+	}
+}
+
+type webhookStore struct {
+	DB *sql.DB
+}
+
+// GetAll returns every subscription without its secret.
+func (s *webhookStore) GetAll(ctx context.Context) ([]webhookEntity.Subscription, error) {
+	query := `
+		SELECT id, url, description, events, active, created_at, updated_at
+		FROM webhook_subscriptions
+		ORDER BY id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []webhookEntity.Subscription{}
+	for rows.Next() {
+		var sub webhookEntity.Subscription
+		err := rows.Scan(&sub.ID, &sub.URL, &sub.Description, pq.Array(&sub.Events), &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// GetById returns a subscription without its secret.
+func (s *webhookStore) GetById(ctx context.Context, id int64) (*webhookEntity.Subscription, error) {
+	query := `
+		SELECT id, url, description, events, active, created_at, updated_at
+		FROM webhook_subscriptions
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var sub webhookEntity.Subscription
+	err := s.DB.QueryRowContext(ctx, query, id).Scan(
+		&sub.ID, &sub.URL, &sub.Description, pq.Array(&sub.Events), &sub.Active, &sub.CreatedAt, &sub.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &sub, nil
+}
+
+func (s *webhookStore) Create(ctx context.Context, sub *webhookEntity.Subscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (url, description, events, secret, active)
+		VALUES ($1, $2, $3, $4, $5) RETURNING id, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	return s.DB.QueryRowContext(
+		ctx,
+		query,
+		sub.URL,
+		sub.Description,
+		pq.Array(sub.Events),
+		sub.Secret,
+		sub.Active,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+}
+
+// Update changes everything but the secret.
+func (s *webhookStore) Update(ctx context.Context, sub *webhookEntity.Subscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $2, description = $3, events = $4, active = $5, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.DB.QueryRowContext(
+		ctx,
+		query,
+		sub.ID,
+		sub.URL,
+		sub.Description,
+		pq.Array(sub.Events),
+		sub.Active,
+	).Scan(&sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the subscription together with its delivery log.
+func (s *webhookStore) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.DB.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return repository.ErrNotFound
+	}
+
+	return nil
+}
+
+// GetDeliveries returns the latest deliveries of a subscription, newest
+// first.
+func (s *webhookStore) GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhookEntity.Delivery, error) {
+	query := `SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		WHERE d.subscription_id = $1
+		ORDER BY d.id DESC
+		LIMIT $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.DB.QueryContext(ctx, query, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []webhookEntity.Delivery{}
+	for rows.Next() {
+		var d webhookEntity.Delivery
+		if err := rows.Scan(deliveryFields(&d)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// CreateDelivery queues a delivery that is not backed by an outbox event,
+// such as a test event. It is due right away.
+func (s *webhookStore) CreateDelivery(ctx context.Context, d *webhookEntity.Delivery) error {
+	query := `
+		INSERT INTO webhook_deliveries AS d (subscription_id, event_id, event_type, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + deliveryColumns
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	err := s.DB.QueryRowContext(ctx, query, d.SubscriptionID, d.EventID, d.EventType, []byte(d.Payload)).Scan(deliveryFields(d)...)
+	if err != nil {
+		if err.Error() == `pq: insert or update on table "webhook_deliveries" violates foreign key constraint "webhook_deliveries_subscription_id_fkey"` {
+			return repository.ErrNotFound
+		}
+		return err
+	}
+
+	return nil
+}
+
+// FanOut queues evt for every active subscription that wants it and
+// returns how many deliveries were created. Running it again for the same
+// event creates nothing, so the outbox may retry it.
+func (s *webhookStore) FanOut(ctx context.Context, evt eventEntity.Event) (int64, error) {
+	payload, err := json.Marshal(evt)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+		SELECT id, $1::bigint, $2::text, $3::jsonb
+		FROM webhook_subscriptions
+		WHERE active AND (cardinality(events) = 0 OR $2::text = ANY(events))
+		ON CONFLICT (subscription_id, event_id) DO NOTHING
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	res, err := s.DB.ExecContext(ctx, query, evt.ID, evt.Type, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ClaimDue returns up to limit due deliveries of active subscriptions and
+// moves their next attempt lease ahead, so other deliverers skip them
+// while they are sent. A deliverer that dies mid-send leaves the
+// delivery to be picked up again once the lease runs out.
+func (s *webhookStore) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]webhookEntity.Job, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + $2 * interval '1 second'
+		FROM webhook_subscriptions s
+		WHERE s.id = d.subscription_id
+		AND d.id IN (
+			SELECT due.id
+			FROM webhook_deliveries due
+			JOIN webhook_subscriptions sub ON sub.id = due.subscription_id
+			WHERE due.status = 'pending'
+			AND due.next_attempt_at <= CURRENT_TIMESTAMP
+			AND (sub.active OR due.event_type = '` + webhookEntity.EventTest + `')
+			ORDER BY due.next_attempt_at, due.id
+			LIMIT $1
+			FOR UPDATE OF due SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns + `, s.url, s.secret
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	rows, err := s.DB.QueryContext(ctx, query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []webhookEntity.Job
+	for rows.Next() {
+		var job webhookEntity.Job
+		if err := rows.Scan(append(deliveryFields(&job.Delivery), &job.URL, &job.Secret)...); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, rows.Err()
+}
+
+func (s *webhookStore) RecordAttempt(ctx context.Context, a webhookEntity.Attempt) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET attempts = attempts + 1,
+			last_attempt_at = CURRENT_TIMESTAMP,
+			last_status_code = $2,
+			last_error = $3,
+			status = $4,
+			next_attempt_at = CURRENT_TIMESTAMP + $5 * interval '1 second',
+			delivered_at = CASE WHEN $6 THEN CURRENT_TIMESTAMP ELSE delivered_at END
+		WHERE id = $1
+	`
+
+	var statusCode sql.NullInt64
+	if a.StatusCode != 0 {
+		statusCode = sql.NullInt64{Int64: int64(a.StatusCode), Valid: true}
+	}
+	var lastError sql.NullString
+	if a.Err != nil {
+		lastError = sql.NullString{String: a.Err.Error(), Valid: true}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	_, err := s.DB.ExecContext(ctx, query,
+		a.DeliveryID,
+		statusCode,
+		lastError,
+		a.Status,
+		a.RetryIn.Seconds(),
+		a.Status == webhookEntity.StatusSucceeded,
+	)
+	return err
+}
+
+// Redeliver puts a delivery back in the queue with a fresh set of
+// attempts, whatever its status.
+func (s *webhookStore) Redeliver(ctx context.Context, subscriptionID int64, id int64) (*webhookEntity.Delivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET status = 'pending', attempts = 0, next_attempt_at = CURRENT_TIMESTAMP, delivered_at = NULL
+		WHERE d.subscription_id = $1 AND d.id = $2
+		RETURNING ` + deliveryColumns
+
+	ctx, cancel := context.WithTimeout(ctx, repository.QueryTimeoutDuration)
+	defer cancel()
+
+	var d webhookEntity.Delivery
+	if err := s.DB.QueryRowContext(ctx, query, subscriptionID, id).Scan(deliveryFields(&d)...); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, repository.ErrNotFound
+		}
+		return nil, err
+	}
+
+	return &d, nil
+}
+
+func deliveryFields(d *webhookEntity.Delivery) []any {
+	return []any{
+		&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, (*[]byte)(&d.Payload), &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastAttemptAt, &d.LastStatusCode, &d.LastError, &d.DeliveredAt, &d.CreatedAt,
+	}
+}
@@ -0,0 +1,137 @@
+// Package seed generates synthetic employees. The same profile, count and
+// seed always produce the same employees, so developers and benchmarks can
+// share identical datasets.
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+)
+
+type Profile struct {
+	Name  string
+	Count int
+	Seed  uint64
+	// Employees are hired over the Years before Anchor. A fixed anchor keeps
+	// created_at identical no matter when the data is generated.
+	Anchor time.Time
+	Years  int
+}
+
+var Profiles = map[string]Profile{
+	"small": {
+		Name:   "small",
+		Count:  25,
+		Seed:   1,
+		Anchor: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Years:  2,
+	},
+	"demo": {
+		Name:   "demo",
+		Count:  500,
+		Seed:   42,
+		Anchor: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Years:  5,
+	},
+	"load-test": {
+		Name:   "load-test",
+		Count:  100000,
+		Seed:   7,
+		Anchor: time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Years:  15,
+	},
+}
+
+// ProfileNames lists the profiles in a stable order for help output.
+func ProfileNames() []string {
+	names := make([]string, 0, len(Profiles))
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type position struct {
+	title string
+	// weight is how common the position is relative to the others.
+	weight int
+	// Salaries are normally distributed around mean, never below min.
+	mean, stddev, min float64
+}
+
+var positions = []position{
+	{"Software Engineer", 30, 95000, 18000, 55000},
+	{"Senior Software Engineer", 14, 130000, 20000, 90000},
+	{"Engineering Manager", 4, 155000, 22000, 110000},
+	{"Product Manager", 8, 115000, 20000, 75000},
+	{"Designer", 7, 85000, 15000, 50000},
+	{"DevOps Engineer", 8, 105000, 17000, 65000},
+	{"Data Analyst", 9, 78000, 14000, 45000},
+	{"QA Engineer", 8, 72000, 12000, 45000},
+	{"Customer Support", 10, 48000, 8000, 32000},
+	{"HR Specialist", 2, 62000, 10000, 40000},
+}
+
+var firstNames = []string{
+	"James", "Mary", "John", "Patricia", "Robert", "Jennifer", "Michael", "Linda",
+	"William", "Elizabeth", "David", "Barbara", "Richard", "Susan", "Joseph", "Jessica",
+	"Thomas", "Sarah", "Charles", "Karen", "Ahmad", "Siti", "Budi", "Dewi",
+	"Wei", "Mei", "Hiroshi", "Yuki", "Carlos", "Lucia", "Omar", "Fatima",
+	"Ivan", "Olga", "Lars", "Ingrid", "Kwame", "Amara", "Raj", "Priya",
+}
+
+var lastNames = []string{
+	"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis",
+	"Rodriguez", "Martinez", "Hernandez", "Lopez", "Wilson", "Anderson", "Taylor", "Thomas",
+	"Moore", "Jackson", "Martin", "Lee", "Santoso", "Wijaya", "Nguyen", "Chen",
+	"Tanaka", "Sato", "Kumar", "Patel", "Haddad", "Ivanov", "Larsen", "Mensah",
+}
+
+// Generate returns count employees for the profile using seed. Emails are
+// unique within one generated set.
+func Generate(profile Profile, count int, seed uint64) []employeeEntity.Employee {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+
+	totalWeight := 0
+	for _, p := range positions {
+		totalWeight += p.weight
+	}
+
+	span := profile.Anchor.Sub(profile.Anchor.AddDate(-profile.Years, 0, 0))
+	employees := make([]employeeEntity.Employee, count)
+	for i := range employees {
+		first := firstNames[rng.IntN(len(firstNames))]
+		last := lastNames[rng.IntN(len(lastNames))]
+		pos := pick(rng, totalWeight)
+
+		salary := math.Max(pos.min, pos.mean+rng.NormFloat64()*pos.stddev)
+
+		employees[i] = employeeEntity.Employee{
+			Name:      first + " " + last,
+			Email:     fmt.Sprintf("%s.%s.%d@example.com", strings.ToLower(first), strings.ToLower(last), i+1),
+			Position:  pos.title,
+			Salary:    math.Round(salary/100) * 100,
+			CreatedAt: profile.Anchor.Add(-time.Duration(rng.Int64N(int64(span)))).Truncate(time.Second),
+		}
+	}
+
+	return employees
+}
+
+func pick(rng *rand.Rand, totalWeight int) position {
+	n := rng.IntN(totalWeight)
+	for _, p := range positions {
+		if n < p.weight {
+			return p
+		}
+		n -= p.weight
+	}
+	return positions[len(positions)-1]
+}
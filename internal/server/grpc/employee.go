@@ -0,0 +1,320 @@
+package grpcServer
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	employeev1 "github.com/MaulanaAhmadSulami/juke_test.git/api/proto/employee/v1"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// eventReset tells a watcher that the changes after its after_id are gone.
+const eventReset = "reset"
+
+type employeeServer struct {
+	employeev1.UnimplementedEmployeeServiceServer
+
+	employeeService service.EmployeesService
+	feed            *events.Feed
+	// fields decides which employee fields a caller may read, by the
+	// role authenticate found.
+	fields config.EmployeesConfig
+	// done is closed on shutdown to end the watch streams, GracefulStop
+	// waits for them otherwise.
+	done   <-chan struct{}
+	logger *zap.SugaredLogger
+}
+
+// visible returns the employee fields the caller may read, nil for all of
+// them.
+func (s *employeeServer) visible(ctx context.Context) []string {
+	return s.fields.VisibleFields(auth.Role(ctx))
+}
+
+func (s *employeeServer) GetEmployee(ctx context.Context, req *employeev1.GetEmployeeRequest) (*employeev1.Employee, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid employee id")
+	}
+
+	emp, err := s.employeeService.GetById(ctx, req.GetId())
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err, "failed to get employee", "id", req.GetId())
+	}
+
+	return toProto(emp, s.visible(ctx))
+}
+
+func (s *employeeServer) ListEmployees(ctx context.Context, req *employeev1.ListEmployeesRequest) (*employeev1.ListEmployeesResponse, error) {
+	size := int(req.GetPageSize())
+	switch {
+	case size < 0:
+		return nil, status.Error(codes.InvalidArgument, "page_size cannot be negative")
+	case size == 0:
+		size = defaultPageSize
+	case size > maxPageSize:
+		size = maxPageSize
+	}
+
+	afterID, err := decodePageToken(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+	}
+
+	filter := employeeEntity.ListFilter{AfterID: afterID, Limit: size + 1}
+	if len(req.GetAttributes()) > 0 {
+		filter.Attributes = make(map[string]any, len(req.GetAttributes()))
+		for name, value := range req.GetAttributes() {
+			filter.Attributes[name] = value
+		}
+	}
+
+	// One employee more than asked for tells whether there is a next page.
+	emps, err := s.employeeService.GetAll(ctx, filter)
+	if err != nil {
+		return nil, toStatus(ctx, s.logger, err, "failed to list employees")
+	}
+
+	resp := &employeev1.ListEmployeesResponse{}
+	if len(emps) > size {
+		emps = emps[:size]
+		resp.NextPageToken = encodePageToken(emps[size-1].ID)
+	}
+	for i := range emps {
+		emp, err := toProto(&emps[i], s.visible(ctx))
+		if err != nil {
+			return nil, err
+		}
+		resp.Employees = append(resp.Employees, emp)
+	}
+
+	return resp, nil
+}
+
+func (s *employeeServer) CreateEmployee(ctx context.Context, req *employeev1.CreateEmployeeRequest) (*employeev1.Employee, error) {
+	if req.GetEmployee() == nil {
+		return nil, status.Error(codes.InvalidArgument, "employee is required")
+	}
+
+	emp := fromProto(req.GetEmployee())
+	emp.ID = 0
+	if err := s.employeeService.Create(ctx, emp); err != nil {
+		return nil, toStatus(ctx, s.logger, err, "failed to create employee")
+	}
+
+	return toProto(emp, s.visible(ctx))
+}
+
+func (s *employeeServer) UpdateEmployee(ctx context.Context, req *employeev1.UpdateEmployeeRequest) (*employeev1.Employee, error) {
+	if req.GetEmployee() == nil {
+		return nil, status.Error(codes.InvalidArgument, "employee is required")
+	}
+	if req.GetEmployee().GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid employee id")
+	}
+
+	emp := fromProto(req.GetEmployee())
+	if err := s.employeeService.Update(ctx, emp); err != nil {
+		return nil, toStatus(ctx, s.logger, err, "failed to update employee", "id", emp.ID)
+	}
+
+	return toProto(emp, s.visible(ctx))
+}
+
+func (s *employeeServer) DeleteEmployee(ctx context.Context, req *employeev1.DeleteEmployeeRequest) (*employeev1.DeleteEmployeeResponse, error) {
+	if req.GetId() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "invalid employee id")
+	}
+
+	if err := s.employeeService.Delete(ctx, req.GetId()); err != nil {
+		return nil, toStatus(ctx, s.logger, err, "failed to delete employee", "id", req.GetId())
+	}
+
+	return &employeev1.DeleteEmployeeResponse{}, nil
+}
+
+// WatchEmployees follows the same change feed as GET /employees/events.
+func (s *employeeServer) WatchEmployees(req *employeev1.WatchEmployeesRequest, stream employeev1.EmployeeService_WatchEmployeesServer) error {
+	ctx := stream.Context()
+	if req.GetAfterId() < 0 {
+		return status.Error(codes.InvalidArgument, "after_id cannot be negative")
+	}
+
+	// Subscribe before replaying, so nothing recorded in between is lost.
+	// Changes that arrive both ways are skipped by id below.
+	client, err := s.feed.Subscribe()
+	if err != nil {
+		return toStatus(ctx, s.logger, err, "failed to watch employees")
+	}
+	defer s.feed.Unsubscribe(client)
+
+	send := func(evt eventEntity.Event) error {
+		msg, err := toProtoEvent(evt, s.visible(ctx))
+		if err != nil {
+			return err
+		}
+		return stream.Send(msg)
+	}
+
+	lastID := int64(-1)
+	if req.GetAfterId() > 0 {
+		lastID, err = s.feed.Replay(ctx, req.GetAfterId(), send)
+		switch {
+		case errors.Is(err, events.ErrHistoryGone):
+			lastID = -1
+			if err := stream.Send(&employeev1.EmployeeEvent{Type: eventReset}); err != nil {
+				return err
+			}
+		case err != nil:
+			return toStatus(ctx, s.logger, err, "failed to replay employee changes")
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-s.done:
+			return status.Error(codes.Unavailable, events.ErrFeedClosed.Error())
+
+		case evt, ok := <-client.Events():
+			if !ok {
+				if errors.Is(client.Err(), events.ErrSlowClient) {
+					s.logger.Infow("disconnected slow watch client", "last_id", lastID)
+					return status.Error(codes.ResourceExhausted, client.Err().Error())
+				}
+				return status.Error(codes.Unavailable, events.ErrFeedClosed.Error())
+			}
+			if evt.ID <= lastID {
+				continue
+			}
+			if err := send(evt); err != nil {
+				return err
+			}
+			lastID = evt.ID
+		}
+	}
+}
+
+func encodePageToken(lastID int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(lastID, 10)))
+}
+
+func decodePageToken(token string) (int64, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid page token")
+	}
+	return id, nil
+}
+
+// toProto converts emp with the fields missing from visible left at their
+// zero value, all of them for a nil visible.
+func toProto(emp *employeeEntity.Employee, visible []string) (*employeev1.Employee, error) {
+	redacted := employeeEntity.Redact(*emp, visible)
+	emp = &redacted
+	msg := &employeev1.Employee{
+		Id:        emp.ID,
+		Name:      emp.Name,
+		Email:     emp.Email,
+		Position:  emp.Position,
+		Salary:    emp.Salary,
+		ManagerId: emp.ManagerID,
+	}
+	if !emp.CreatedAt.IsZero() {
+		msg.CreatedAt = timestamppb.New(emp.CreatedAt)
+	}
+	if len(emp.Attributes) > 0 {
+		attrs, err := structpb.NewStruct(emp.Attributes)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "employee %d has attributes that cannot be encoded", emp.ID)
+		}
+		msg.Attributes = attrs
+	}
+	return msg, nil
+}
+
+func fromProto(msg *employeev1.Employee) *employeeEntity.Employee {
+	emp := &employeeEntity.Employee{
+		ID:       msg.GetId(),
+		Name:     msg.GetName(),
+		Email:    msg.GetEmail(),
+		Position: msg.GetPosition(),
+		Salary:   msg.GetSalary(),
+	}
+	if msg.ManagerId != nil {
+		managerID := msg.GetManagerId()
+		emp.ManagerID = &managerID
+	}
+	if msg.GetAttributes() != nil {
+		emp.Attributes = msg.GetAttributes().AsMap()
+	}
+	return emp
+}
+
+// changedRow is an employees row as the change trigger stores it. Its
+// timestamps have no time zone, which time.Time refuses to decode.
+type changedRow struct {
+	employeeEntity.Employee
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func toProtoEvent(evt eventEntity.Event, visible []string) (*employeev1.EmployeeEvent, error) {
+	var row changedRow
+	if err := json.Unmarshal(evt.Data, &row); err != nil {
+		return nil, status.Errorf(codes.Internal, "change %d cannot be decoded", evt.ID)
+	}
+	if createdAt, err := parseRowTime(row.CreatedAt); err == nil {
+		row.Employee.CreatedAt = createdAt
+	}
+	if updatedAt, err := parseRowTime(row.UpdatedAt); err == nil {
+		row.Employee.UpdatedAt = updatedAt
+	}
+
+	emp, err := toProto(&row.Employee, visible)
+	if err != nil {
+		return nil, err
+	}
+
+	return &employeev1.EmployeeEvent{
+		Id:         evt.ID,
+		Type:       evt.Type,
+		EmployeeId: evt.EmployeeID,
+		Employee:   emp,
+		CreatedAt:  timestamppb.New(evt.CreatedAt),
+	}, nil
+}
+
+func parseRowTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02T15:04:05.999999999", s)
+}
@@ -0,0 +1,45 @@
+package grpcServer
+
+import (
+	"context"
+	"errors"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// toStatus maps service and repository errors to the gRPC status the REST
+// handlers answer with the matching HTTP code. Anything unknown is logged
+// and hidden behind Internal.
+func toStatus(ctx context.Context, logger *zap.SugaredLogger, err error, msg string, keysAndValues ...any) error {
+	var invalidEmployee employeeEntity.ValidationError
+	var invalidAttributes *attributeEntity.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return status.Error(codes.NotFound, "not found")
+	case errors.Is(err, repository.ErrUniqueViolation):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, repository.ErrNullEmail),
+		errors.Is(err, repository.ErrNullOrNegSalary),
+		errors.Is(err, repository.ErrManagerNotFound),
+		errors.As(err, &invalidEmployee),
+		errors.As(err, &invalidAttributes):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, events.ErrTooManyClients):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, events.ErrFeedClosed):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, context.Canceled) && ctx.Err() != nil:
+		return status.Error(codes.Canceled, "request canceled")
+	case errors.Is(err, context.DeadlineExceeded) && ctx.Err() != nil:
+		return status.Error(codes.DeadlineExceeded, "deadline exceeded")
+	default:
+		logger.Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		return status.Error(codes.Internal, "internal server error")
+	}
+}
@@ -0,0 +1,206 @@
+// Package grpcServer serves the employee API over gRPC, next to the HTTP
+// server and backed by the same services.
+package grpcServer
+
+import (
+	"context"
+	"net"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	employeev1 "github.com/MaulanaAhmadSulami/juke_test.git/api/proto/employee/v1"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+type Server struct {
+	grpc   *grpc.Server
+	health *health.Server
+	done   chan struct{}
+	once   sync.Once
+	// tokens and roles are API_TOKENS and API_ROLES.
+	tokens map[string]string
+	roles  map[string]string
+	logger *zap.SugaredLogger
+	// accessLog leaves out callers and stack traces, they are the same
+	// for every call.
+	accessLog *zap.SugaredLogger
+}
+
+func NewServer(
+	employeeService service.EmployeesService,
+	feed *events.Feed,
+	cfg config.GRPCConfig,
+	fields config.EmployeesConfig,
+	tokens map[string]string,
+	roles map[string]string,
+	logger *zap.SugaredLogger,
+) *Server {
+	s := &Server{
+		health: health.NewServer(),
+		done:   make(chan struct{}),
+		tokens: tokens,
+		roles:  roles,
+		logger: logger,
+
+		accessLog: logger.Desugar().WithOptions(zap.WithCaller(false), zap.AddStacktrace(zapcore.FatalLevel)).Sugar(),
+	}
+	s.grpc = grpc.NewServer(
+		grpc.ChainUnaryInterceptor(s.logUnary, s.recoverUnary, s.authUnary),
+		grpc.ChainStreamInterceptor(s.logStream, s.recoverStream, s.authStream),
+	)
+
+	employeev1.RegisterEmployeeServiceServer(s.grpc, &employeeServer{
+		employeeService: employeeService,
+		feed:            feed,
+		fields:          fields,
+		done:            s.done,
+		logger:          logger,
+	})
+
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	s.health.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	s.health.SetServingStatus(employeev1.EmployeeService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+
+	if cfg.Reflection {
+		reflection.Register(s.grpc)
+	}
+	return s
+}
+
+// Serve accepts connections until Shutdown. It returns nil after a
+// shutdown.
+func (s *Server) Serve(lis net.Listener) error {
+	if err := s.grpc.Serve(lis); err != nil && err != grpc.ErrServerStopped {
+		return err
+	}
+	return nil
+}
+
+// Shutdown reports NOT_SERVING, ends the watch streams and waits for the
+// other calls to finish. Calls still running when ctx is done are cut off.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.health.Shutdown()
+	s.once.Do(func() { close(s.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		s.grpc.Stop()
+		return ctx.Err()
+	}
+}
+
+func (s *Server) recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer s.recover(info.FullMethod, &err)
+	return handler(ctx, req)
+}
+
+func (s *Server) recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer s.recover(info.FullMethod, &err)
+	return handler(srv, ss)
+}
+
+func (s *Server) recover(method string, err *error) {
+	if p := recover(); p != nil {
+		s.logger.Errorw("panic in grpc handler", "method", method, "panic", p, "stack", string(debug.Stack()))
+		*err = status.Error(codes.Internal, "internal server error")
+	}
+}
+
+func (s *Server) authUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authenticate checks the authorization metadata of employee service calls
+// like auth.Identify does the Authorization header: calls without one are
+// anonymous, ones with an unknown token are rejected. Health checks and
+// reflection are left alone.
+func (s *Server) authenticate(ctx context.Context, method string) (context.Context, error) {
+	if !strings.HasPrefix(method, "/"+employeev1.EmployeeService_ServiceDesc.ServiceName+"/") {
+		return ctx, nil
+	}
+
+	values := metadata.ValueFromIncomingContext(ctx, "authorization")
+	if len(values) == 0 {
+		return ctx, nil
+	}
+	ctx, ok := auth.WithCaller(ctx, s.tokens, s.roles, values[0])
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "unauthorized")
+	}
+	return ctx, nil
+}
+
+// authenticatedStream hands the handler the context authenticate made.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context { return s.ctx }
+
+func (s *Server) logUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	s.access(info.FullMethod, start, err)
+	return resp, err
+}
+
+func (s *Server) logStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	s.access(info.FullMethod, start, err)
+	return err
+}
+
+// access writes one line per call, like the HTTP access log.
+func (s *Server) access(method string, start time.Time, err error) {
+	code := status.Code(err)
+	fields := []any{
+		"method", method,
+		"code", code.String(),
+		"latency_ms", float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	switch code {
+	case codes.OK, codes.Canceled:
+		s.accessLog.Infow("grpc call", fields...)
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unimplemented:
+		s.accessLog.Errorw("grpc call", fields...)
+	default:
+		s.accessLog.Warnw("grpc call", fields...)
+	}
+}
@@ -0,0 +1,134 @@
+// Package auth authenticates API callers with static bearer tokens.
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+type (
+	principalKey struct{}
+	roleKey      struct{}
+)
+
+// RoleAdmin is the role admin routes, like attribute definitions, require.
+const RoleAdmin = "admin"
+
+// RequireToken rejects requests without a known bearer token and stores the
+// name the token belongs to in the request context. With no tokens
+// configured every request is rejected.
+func RequireToken(tokens map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			name, ok := lookup(tokens, r.Header.Get("Authorization"))
+			if !ok {
+				Unauthorized(w)
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), principalKey{}, name)
+			logging.AddFields(ctx, "principal", name)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Identify is RequireToken for routes anonymous callers may use too:
+// requests without an Authorization header pass through without a
+// principal, ones with an unknown token are still rejected. roles maps
+// principal names to the role Role returns for them.
+func Identify(tokens map[string]string, roles map[string]string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header := r.Header.Get("Authorization")
+			if header == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			ctx, ok := WithCaller(r.Context(), tokens, roles, header)
+			if !ok {
+				Unauthorized(w)
+				return
+			}
+
+			logging.AddFields(ctx, "principal", Principal(ctx))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// WithCaller checks an Authorization value against tokens and returns ctx
+// with the principal and its role in roles, which Principal and Role read.
+// It is false for a missing or unknown token. Identify uses it, and so can
+// servers other than HTTP.
+func WithCaller(ctx context.Context, tokens map[string]string, roles map[string]string, authorization string) (context.Context, bool) {
+	name, ok := lookup(tokens, authorization)
+	if !ok {
+		return ctx, false
+	}
+
+	ctx = context.WithValue(ctx, principalKey{}, name)
+	if role, ok := roles[name]; ok {
+		ctx = context.WithValue(ctx, roleKey{}, role)
+	}
+	return ctx, true
+}
+
+// RequireRole answers 403 unless the caller authenticated by RequireToken
+// has one of the allowed roles in roles, which maps principal names to
+// roles like API_ROLES.
+func RequireRole(roles map[string]string, allowed ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := roles[Principal(r.Context())]
+			if !ok || !slices.Contains(allowed, role) {
+				protocol.WriteJSONError(w, http.StatusForbidden, "forbidden")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), roleKey{}, role)))
+		})
+	}
+}
+
+// Principal returns the name of the authenticated caller, or an empty string
+// for unauthenticated requests.
+func Principal(ctx context.Context) string {
+	name, _ := ctx.Value(principalKey{}).(string)
+	return name
+}
+
+// Role returns the role of the caller identified by Identify or WithCaller
+// or checked by RequireRole, or an empty string for anonymous callers and those without a
+// role.
+func Role(ctx context.Context) string {
+	role, _ := ctx.Value(roleKey{}).(string)
+	return role
+}
+
+// Unauthorized answers 401 with a bearer challenge, for handlers that
+// require a token for some requests only.
+func Unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	protocol.WriteJSONError(w, http.StatusUnauthorized, "unauthorized")
+}
+
+func lookup(tokens map[string]string, header string) (string, bool) {
+	presented, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || presented == "" {
+		return "", false
+	}
+
+	for token, name := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(presented)) == 1 {
+			return name, true
+		}
+	}
+	return "", false
+}
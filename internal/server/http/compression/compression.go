@@ -0,0 +1,274 @@
+// Package compression compresses HTTP answers with zstd, brotli or gzip as
+// the Accept-Encoding header of the request allows. Answers are buffered up
+// to a threshold first, so small ones, which gain little, are sent as they
+// are.
+package compression
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+)
+
+// encoder is what the writers of every coding have in common.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+// pools keeps idle encoders per coding, they are costly to set up.
+var pools = map[string]*sync.Pool{
+	"zstd": {New: func() any {
+		enc, _ := zstd.NewWriter(nil, zstd.WithEncoderConcurrency(1), zstd.WithEncoderLevel(zstd.SpeedDefault))
+		return enc
+	}},
+	"br": {New: func() any {
+		return brotli.NewWriterLevel(nil, brotli.DefaultCompression)
+	}},
+	"gzip": {New: func() any {
+		enc, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return enc
+	}},
+}
+
+// Middleware compresses answers of cfg.MinBytes or more in one of
+// cfg.Encodings, which are preferred in their order when the client accepts
+// several equally. Only text-like types are compressed; event streams,
+// ranges and answers that already have a Content-Encoding are left alone.
+func Middleware(cfg config.CompressionConfig) (func(http.Handler) http.Handler, error) {
+	for _, name := range cfg.Encodings {
+		if _, ok := pools[name]; !ok {
+			return nil, fmt.Errorf("unsupported encoding %q", name)
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(cfg.Encodings) == 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			cw := &writer{
+				ResponseWriter: w,
+				encoding:       negotiate(r.Header.Get("Accept-Encoding"), cfg.Encodings),
+				minBytes:       cfg.MinBytes,
+			}
+			defer cw.close()
+			next.ServeHTTP(cw, r)
+		})
+	}, nil
+}
+
+// negotiate picks the coding with the highest q-value in header, in the
+// order of encodings on ties. It returns "" when none is acceptable.
+func negotiate(header string, encodings []string) string {
+	if header == "" {
+		return ""
+	}
+
+	accepted := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(part, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if parsed, err := strconv.ParseFloat(value, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if name != "" {
+			accepted[name] = q
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, name := range encodings {
+		q, ok := accepted[name]
+		if !ok {
+			q = accepted["*"]
+		}
+		if q > bestQ {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressible reports whether answers of mediaType are worth compressing.
+func compressible(mediaType string) bool {
+	switch {
+	case mediaType == "text/event-stream":
+		return false
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml",
+		"application/msgpack", "application/javascript":
+		return true
+	}
+	return false
+}
+
+// writer holds the status and first bytes of an answer back until it knows
+// whether to compress it.
+type writer struct {
+	http.ResponseWriter
+	encoding string
+	minBytes int
+
+	status  int
+	decided bool
+	buf     []byte
+	enc     encoder
+}
+
+func (w *writer) WriteHeader(status int) {
+	if w.decided || w.status != 0 {
+		return
+	}
+	if status < http.StatusOK {
+		w.ResponseWriter.WriteHeader(status)
+		return
+	}
+	w.status = status
+	if !w.eligible() {
+		w.start(false)
+	}
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.minBytes {
+			return len(p), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Flush sends what is buffered. A handler flushing is streaming, so the
+// answer is compressed from here on whatever its size.
+func (w *writer) Flush() error {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if !w.decided {
+		if err := w.start(true); err != nil {
+			return err
+		}
+	}
+	if w.enc != nil {
+		if err := w.enc.Flush(); err != nil {
+			return err
+		}
+	}
+	return http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (w *writer) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// eligible reports whether the answer may still be compressed once it is
+// large enough, adding Vary for the ones that could be.
+func (w *writer) eligible() bool {
+	header := w.Header()
+	if w.status == http.StatusNoContent || w.status == http.StatusNotModified || w.status == http.StatusPartialContent ||
+		header.Get("Content-Encoding") != "" || header.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType := header.Get("Content-Type")
+	if mediaType == "" {
+		// Decided on the first write, once the type can be sniffed.
+		return true
+	}
+	if parsed, _, err := mime.ParseMediaType(mediaType); err != nil || !compressible(parsed) {
+		return false
+	}
+	header.Add("Vary", "Accept-Encoding")
+	return true
+}
+
+// start writes the header, compressed when compress is set and the answer
+// qualifies, followed by the buffered bytes.
+func (w *writer) start(compress bool) error {
+	w.decided = true
+	header := w.Header()
+	if header.Get("Content-Type") == "" && len(w.buf) > 0 {
+		header.Set("Content-Type", http.DetectContentType(w.buf))
+		if !w.eligible() {
+			compress = false
+		}
+	}
+
+	if compress && w.encoding != "" {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		w.enc = pools[w.encoding].Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close sends an answer that stayed below the threshold and finishes a
+// compressed one.
+func (w *writer) close() {
+	if !w.decided {
+		if w.status == 0 {
+			// Nothing was written, net/http answers 200 itself.
+			return
+		}
+		w.start(false)
+		return
+	}
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(nil)
+		pools[w.encoding].Put(w.enc)
+		w.enc = nil
+	}
+}
@@ -0,0 +1,61 @@
+package adminHandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+type HttpHandler struct {
+	logger *logging.Logger
+}
+
+func newHttpHandler(logger *logging.Logger) *HttpHandler {
+	return &HttpHandler{
+		logger: logger,
+	}
+}
+
+// LoggingSettings is the runtime log configuration.
+type LoggingSettings struct {
+	Level  string `json:"level" example:"info"`
+	Format string `json:"format" example:"json"`
+}
+
+func (h *HttpHandler) GetLogging(w http.ResponseWriter, r *http.Request) {
+	protocol.WriteJSON(w, http.StatusOK, LoggingSettings{
+		Level:  h.logger.Level(),
+		Format: h.logger.Format(),
+	})
+}
+
+func (h *HttpHandler) UpdateLogging(w http.ResponseWriter, r *http.Request) {
+	var req LoggingSettings
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if req.Level != "" {
+		if err := h.logger.SetLevel(req.Level); err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	if req.Format != "" {
+		if err := h.logger.SetFormat(req.Format); err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	h.logger.Infow("log settings changed",
+		"level", h.logger.Level(),
+		"format", h.logger.Format(),
+		"principal", auth.Principal(r.Context()),
+	)
+	h.GetLogging(w, r)
+}
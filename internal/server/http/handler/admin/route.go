@@ -0,0 +1,17 @@
+package adminHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoute expects an authenticated caller; changing the log settings
+// additionally takes the admin role in roles.
+func RegisterRoute(logger *logging.Logger, roles map[string]string) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(logger)
+		r.Get("/logging", handler.GetLogging)
+		r.With(auth.RequireRole(roles, auth.RoleAdmin)).Put("/logging", handler.UpdateLogging)
+	}
+}
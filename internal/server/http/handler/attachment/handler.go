@@ -0,0 +1,160 @@
+package attachmentHandler
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// multipartOverhead leaves room for the form fields and part headers on top
+// of the file itself.
+const multipartOverhead = 1 << 20
+
+type HttpHandler struct {
+	attachmentService service.AttachmentService
+	maxBytes          int64
+	logger            *zap.SugaredLogger
+}
+
+func newHttpHandler(attachmentService service.AttachmentService, maxBytes int64, logger *zap.SugaredLogger) *HttpHandler {
+	return &HttpHandler{
+		attachmentService: attachmentService,
+		maxBytes:          maxBytes,
+		logger:            logger,
+	}
+}
+
+func (h *HttpHandler) GetByEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	attachments, err := h.attachmentService.GetByEmployee(r.Context(), employeeID)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get attachments", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, attachments)
+}
+
+func (h *HttpHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBytes+multipartOverhead)
+	if err := r.ParseMultipartForm(multipartOverhead); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			protocol.WriteJSONError(w, http.StatusRequestEntityTooLarge, repository.ErrAttachmentTooLarge.Error())
+			return
+		}
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid multipart form")
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "file is required")
+		return
+	}
+	defer file.Close()
+
+	att, err := h.attachmentService.Upload(r.Context(), &attachmentEntity.Upload{
+		EmployeeID:     employeeID,
+		Filename:       header.Filename,
+		Category:       r.FormValue("category"),
+		ExpectedSHA256: r.FormValue("sha256"),
+		UploadedBy:     auth.Principal(r.Context()),
+		Body:           file,
+	})
+	if err != nil {
+		h.writeError(w, r, err, "failed to upload attachment", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, att)
+}
+
+func (h *HttpHandler) Download(w http.ResponseWriter, r *http.Request) {
+	employeeID, id, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	att, obj, err := h.attachmentService.Open(r.Context(), employeeID, id)
+	if err != nil {
+		h.writeError(w, r, err, "failed to open attachment", "employee_id", employeeID, "id", id)
+		return
+	}
+	defer obj.Close()
+
+	w.Header().Set("Content-Type", att.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": att.Filename}))
+	w.Header().Set("X-Checksum-SHA256", att.SHA256)
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, att.SHA256))
+	http.ServeContent(w, r, att.Filename, att.CreatedAt, obj)
+}
+
+func (h *HttpHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	employeeID, id, ok := parseIDs(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.Delete(r.Context(), employeeID, id); err != nil {
+		h.writeError(w, r, err, "failed to delete attachment", "employee_id", employeeID, "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, "deleted successfully")
+}
+
+func parseIDs(w http.ResponseWriter, r *http.Request) (int64, int64, bool) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return 0, 0, false
+	}
+
+	id, err := strconv.ParseInt(chi.URLParam(r, "attachmentId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid attachment id")
+		return 0, 0, false
+	}
+
+	return employeeID, id, true
+}
+
+func (h *HttpHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string, keysAndValues ...any) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		protocol.WriteJSONError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, repository.ErrAttachmentTooLarge):
+		protocol.WriteJSONError(w, http.StatusRequestEntityTooLarge, err.Error())
+	case errors.Is(err, repository.ErrUnsupportedMediaType):
+		protocol.WriteJSONError(w, http.StatusUnsupportedMediaType, err.Error())
+	case errors.Is(err, repository.ErrChecksumMismatch):
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.FromContext(r.Context(), h.logger).Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,22 @@
+package attachmentHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// RegisterRoute registers absolute paths, see leaveHandler.RegisterRoute.
+func RegisterRoute(
+	attachmentService service.AttachmentService,
+	maxBytes int64,
+	logger *zap.SugaredLogger,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(attachmentService, maxBytes, logger)
+		r.Get("/api/v1/employees/{employeeId}/attachments", handler.GetByEmployee)
+		r.Post("/api/v1/employees/{employeeId}/attachments", handler.Upload)
+		r.Get("/api/v1/employees/{employeeId}/attachments/{attachmentId}", handler.Download)
+		r.Delete("/api/v1/employees/{employeeId}/attachments/{attachmentId}", handler.Delete)
+	}
+}
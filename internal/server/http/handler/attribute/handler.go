@@ -0,0 +1,81 @@
+package attributeHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type HttpHandler struct {
+	attributeService service.AttributeService
+	logger           *zap.SugaredLogger
+}
+
+func newHttpHandler(attributeService service.AttributeService, logger *zap.SugaredLogger) *HttpHandler {
+	return &HttpHandler{
+		attributeService: attributeService,
+		logger:           logger,
+	}
+}
+
+func (h *HttpHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	defs, err := h.attributeService.GetDefinitions(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context(), h.logger).Errorw("failed to get attribute definitions", "error", err)
+		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, defs)
+}
+
+func (h *HttpHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var def attributeEntity.Definition
+	if err := json.NewDecoder(r.Body).Decode(&def); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.attributeService.CreateDefinition(r.Context(), &def); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrAttributeExists):
+			protocol.WriteJSONError(w, http.StatusConflict, err.Error())
+		default:
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to create attribute definition", "error", err)
+			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, def)
+}
+
+func (h *HttpHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "attributeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid attribute id")
+		return
+	}
+
+	if err := h.attributeService.DeleteDefinition(r.Context(), id); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrNotFound):
+			protocol.WriteJSONError(w, http.StatusNotFound, "attribute not found")
+		default:
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to delete attribute definition", "error", err, "id", id)
+			protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, "deleted successfully")
+}
@@ -0,0 +1,19 @@
+package attributeHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func RegisterRoute(
+	attributeService service.AttributeService,
+	logger *zap.SugaredLogger,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(attributeService, logger)
+		r.Get("/", handler.GetAll)
+		r.Post("/", handler.Create)
+		r.Delete("/{attributeId}", handler.Delete)
+	}
+}
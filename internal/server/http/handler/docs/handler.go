@@ -0,0 +1,57 @@
+package docsHandler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/api/openapi"
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+type HttpHandler struct {
+	json []byte
+}
+
+func newHttpHandler(doc *openapi3.T) (*HttpHandler, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return &HttpHandler{json: body}, nil
+}
+
+// YAML serves the OpenAPI document as it is written in api/openapi.
+func (h *HttpHandler) YAML(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(openapi.Spec)
+}
+
+// JSON serves the OpenAPI document converted to JSON, for tools that do not
+// read YAML.
+func (h *HttpHandler) JSON(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(h.json)
+}
+
+// SwaggerUI serves Swagger UI for /openapi.yaml.
+func (h *HttpHandler) SwaggerUI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(swaggerUIPage))
+}
+
+const swaggerUIPage = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>CRUD API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+  <script crossorigin src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.yaml", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,24 @@
+package docsHandler
+
+import (
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/go-chi/chi/v5"
+)
+
+// RegisterRoute mounts the OpenAPI document at /openapi.yaml and
+// /openapi.json and Swagger UI at /swagger/.
+func RegisterRoute(doc *openapi3.T) (func(chi.Router), error) {
+	handler, err := newHttpHandler(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r chi.Router) {
+		r.Get("/openapi.yaml", handler.YAML)
+		r.Get("/openapi.json", handler.JSON)
+		r.Get("/swagger", http.RedirectHandler("/swagger/", http.StatusMovedPermanently).ServeHTTP)
+		r.Get("/swagger/*", handler.SwaggerUI)
+	}, nil
+}
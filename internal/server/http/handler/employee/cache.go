@@ -0,0 +1,52 @@
+package employeeHandler
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+// withTimestamps adds the columns notModified needs to a projection, they
+// are read but only encoded when fields asks for them.
+func withTimestamps(fields []string) []string {
+	if fields == nil {
+		return nil
+	}
+	out := slices.Clone(fields)
+	for _, field := range []string{"created_at", "updated_at"} {
+		if !slices.Contains(out, field) {
+			out = append(out, field)
+		}
+	}
+	return out
+}
+
+// notModified sets the caching headers of an answer with employees and
+// answers 304 when the client's copy is current. Last-Modified is the latest
+// change of a returned employee; the ETag also covers which employees were
+// returned, so a deletion changes it, and the fields and format of the
+// answer.
+func (h *HttpHandler) notModified(w http.ResponseWriter, r *http.Request, employees []employeeEntity.Employee, fields []string) bool {
+	var lastModified time.Time
+	hash := fnv.New64a()
+	for i := range employees {
+		emp := &employees[i]
+		for _, t := range []time.Time{emp.CreatedAt, emp.UpdatedAt} {
+			if t.After(lastModified) {
+				lastModified = t
+			}
+		}
+		fmt.Fprintf(hash, "%d@%d,", emp.ID, emp.UpdatedAt.UnixNano())
+	}
+	fmt.Fprintf(hash, "%s;%s", strings.Join(fields, ","), r.Header.Get("Accept"))
+
+	etag := strconv.FormatUint(hash.Sum64(), 36)
+	return protocol.NotModified(w, r, lastModified, etag, h.cfg.CacheMaxAge)
+}
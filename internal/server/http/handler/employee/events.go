@@ -0,0 +1,140 @@
+package employeeHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	eventEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+// streamWriteTimeout bounds every write to an event stream. The server
+// write timeout is meant for whole responses and would end the stream.
+const streamWriteTimeout = 10 * time.Second
+
+func (h *HttpHandler) Events(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	lastID := int64(-1)
+	lastIDStr := r.Header.Get("Last-Event-ID")
+	if lastIDStr == "" {
+		lastIDStr = r.URL.Query().Get("last_event_id")
+	}
+	if lastIDStr != "" {
+		id, err := strconv.ParseInt(lastIDStr, 10, 64)
+		if err != nil || id < 0 {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "invalid last event id")
+			return
+		}
+		lastID = id
+	}
+
+	// Subscribe before replaying, so nothing recorded in between is lost.
+	// Changes that arrive both ways are skipped by id below.
+	client, err := h.feed.Subscribe()
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusServiceUnavailable, err.Error())
+		return
+	}
+	defer h.feed.Unsubscribe(client)
+
+	// Change events carry the whole row, restricted fields included.
+	visible := h.visibleFields(r)
+	rc := http.NewResponseController(w)
+	send := func(write func(io.Writer) error) error {
+		rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if err := write(w); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := send(func(w io.Writer) error {
+		_, err := io.WriteString(w, "retry: 2000\n\n")
+		return err
+	}); err != nil {
+		return
+	}
+
+	if lastID >= 0 {
+		lastID, err = h.feed.Replay(ctx, lastID, func(evt eventEntity.Event) error {
+			return send(eventWriter(evt, visible))
+		})
+		switch {
+		case errors.Is(err, events.ErrHistoryGone):
+			lastID = -1
+			if send(func(w io.Writer) error {
+				_, err := io.WriteString(w, "event: reset\ndata: {}\n\n")
+				return err
+			}) != nil {
+				return
+			}
+		case err != nil:
+			if ctx.Err() == nil {
+				logging.FromContext(r.Context(), h.logger).Warnw("failed to replay employee changes", "error", err)
+			}
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-heartbeat.C:
+			err := send(func(w io.Writer) error {
+				_, err := io.WriteString(w, ": heartbeat\n\n")
+				return err
+			})
+			if err != nil {
+				return
+			}
+
+		case evt, ok := <-client.Events():
+			if !ok {
+				if errors.Is(client.Err(), events.ErrSlowClient) {
+					logging.FromContext(r.Context(), h.logger).Infow("disconnected slow event stream client", "last_event_id", lastID)
+				}
+				return
+			}
+			if evt.ID <= lastID {
+				continue
+			}
+			if err := send(eventWriter(evt, visible)); err != nil {
+				return
+			}
+			lastID = evt.ID
+		}
+	}
+}
+
+func eventWriter(evt eventEntity.Event, visible []string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		var err error
+		if evt.Data, err = employeeEntity.RedactJSON(evt.Data, visible); err != nil {
+			return err
+		}
+		data, err := json.Marshal(evt)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", evt.ID, evt.Type, data)
+		return err
+	}
+}
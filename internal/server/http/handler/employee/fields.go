@@ -0,0 +1,116 @@
+package employeeHandler
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+)
+
+// employee is the part of an Employee a caller asked for and may see.
+// Fields left out are nil and not encoded.
+type employee struct {
+	ID         *int64         `json:"id,omitempty"`
+	Name       *string        `json:"name,omitempty"`
+	Email      *string        `json:"email,omitempty"`
+	Position   *string        `json:"position,omitempty"`
+	Salary     *float64       `json:"salary,omitempty"`
+	ManagerID  *int64         `json:"manager_id,omitempty"`
+	Attributes map[string]any `json:"attributes,omitempty"`
+	CreatedAt  *time.Time     `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time     `json:"updated_at,omitempty"`
+}
+
+func shape(emp *employeeEntity.Employee, fields []string) employee {
+	var out employee
+	for _, field := range fields {
+		switch field {
+		case "id":
+			out.ID = &emp.ID
+		case "name":
+			out.Name = &emp.Name
+		case "email":
+			out.Email = &emp.Email
+		case "position":
+			out.Position = &emp.Position
+		case "salary":
+			out.Salary = &emp.Salary
+		case "manager_id":
+			out.ManagerID = emp.ManagerID
+		case "attributes":
+			out.Attributes = emp.Attributes
+		case "created_at":
+			out.CreatedAt = &emp.CreatedAt
+		case "updated_at":
+			out.UpdatedAt = &emp.UpdatedAt
+		}
+	}
+	return out
+}
+
+// visibleFields returns the fields the caller of r may see, or nil when
+// that is all of them.
+func (h *HttpHandler) visibleFields(r *http.Request) []string {
+	return h.cfg.VisibleFields(auth.Role(r.Context()))
+}
+
+// selectedFields returns the fields requested with ?fields=, limited to
+// the visible ones, or nil for all of them. Asking for a restricted field
+// is an error rather than silently leaving it out.
+func (h *HttpHandler) selectedFields(r *http.Request) ([]string, error) {
+	visible := h.visibleFields(r)
+	values, ok := r.URL.Query()["fields"]
+	if !ok {
+		return visible, nil
+	}
+
+	fields, err := employeeEntity.ParseFields(strings.Join(values, ","))
+	if err != nil {
+		return nil, err
+	}
+	if visible != nil {
+		for _, field := range fields {
+			if !slices.Contains(visible, field) {
+				return nil, employeeEntity.RestrictedFieldError(field)
+			}
+		}
+	}
+	return fields, nil
+}
+
+// writeEmployees answers with employees limited to fields, all of them for
+// nil fields.
+func writeEmployees(w http.ResponseWriter, r *http.Request, status int, employees []employeeEntity.Employee, fields []string) {
+	if fields == nil {
+		protocol.Write(w, r, status, employees)
+		return
+	}
+	shaped := make([]employee, len(employees))
+	for i := range employees {
+		shaped[i] = shape(&employees[i], fields)
+	}
+	protocol.Write(w, r, status, shaped)
+}
+
+func writeEmployee(w http.ResponseWriter, r *http.Request, status int, emp *employeeEntity.Employee, fields []string) {
+	if fields == nil {
+		protocol.Write(w, r, status, emp)
+		return
+	}
+	protocol.Write(w, r, status, shape(emp, fields))
+}
+
+// writeFieldsError answers a failed selectedFields.
+func writeFieldsError(w http.ResponseWriter, err error) {
+	var restricted employeeEntity.RestrictedFieldError
+	if errors.As(err, &restricted) {
+		protocol.WriteJSONError(w, http.StatusForbidden, err.Error())
+		return
+	}
+	protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+}
@@ -1,12 +1,17 @@
 package employeeHandler
 
 import (
-	"encoding/json"
 	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
 	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
 	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
@@ -16,53 +21,61 @@ import (
 
 type HttpHandler struct {
 	employeeService service.EmployeesService
+	feed *events.Feed
+	heartbeat time.Duration
+	cfg config.EmployeesConfig
 	logger *zap.SugaredLogger
 }
 
-func newHttpHandler(employeeService service.EmployeesService, logger *zap.SugaredLogger) *HttpHandler {
+func newHttpHandler(employeeService service.EmployeesService, feed *events.Feed, heartbeat time.Duration, cfg config.EmployeesConfig, logger *zap.SugaredLogger) *HttpHandler {
 	return &HttpHandler{
 		employeeService: employeeService,
+		feed: feed,
+		heartbeat: heartbeat,
+		cfg: cfg,
 		logger: logger,
 	}
 }
 
 
-// Get Employees godoc
-//
-// @Summary Get All Employees
-// @Description Get a list of all employees available
-// @Tags employees
-// @Accept json
-// @Produce json
-// @Success 200 {object} employeeEntity.Employee
-// @Failure 500 {object} map[string]string	"Internal server error"
-// @Failure 404 {object} map[string]string	"not found"
-// @Router /employees [get]
 func (h *HttpHandler) GetAll(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
-	employees, err := h.employeeService.GetAll(ctx)
+	fields, err := h.selectedFields(r)
 	if err != nil {
-		h.logger.Errorw("failed to get all employees", "error", err)
-		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		writeFieldsError(w, err)
+		return
+	}
+
+	filter := employeeEntity.ListFilter{Fields: withTimestamps(fields)}
+	for key, values := range r.URL.Query() {
+		if name, ok := strings.CutPrefix(key, "attr."); ok && len(values) > 0 {
+			if filter.Attributes == nil {
+				filter.Attributes = map[string]any{}
+			}
+			filter.Attributes[name] = values[0]
+		}
+	}
+
+	employees, err := h.employeeService.GetAll(ctx, filter)
+	if err != nil {
+		var invalid *attributeEntity.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		default:
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to get all employees", "error", err)
+			protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		}
 		return
 	}
 
-	protocol.WriteJSON(w, http.StatusOK, employees)
+	if h.notModified(w, r, employees, fields) {
+		return
+	}
+	writeEmployees(w, r, http.StatusOK, employees, fields)
 }
 
-// GetEmployeeById godoc
-//
-// @Summary Get Employee By ID
-// @Description Get employee By ID
-// @Tags employees
-// @Accept json
-// @Produce json
-// @Param employeeId path int true "Employee ID"
-// @Success 200 {object}  employeeEntity.Employee
-// @Failure 500 {object} map[string]string	"Internal server error"
-// @Failure 404 {object} map[string]string	"not found"
-// @Router /employees/{employeeId} [get]
 func(h *HttpHandler) GetById(w http.ResponseWriter, r *http.Request){
 	ctx := r.Context()
 
@@ -73,37 +86,68 @@ func(h *HttpHandler) GetById(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
+	fields, err := h.selectedFields(r)
+	if err != nil {
+		writeFieldsError(w, err)
+		return
+	}
+	if fields != nil {
+		h.getFields(w, r, id, fields)
+		return
+	}
+
 	employee, err := h.employeeService.GetById(ctx, id)
 	if err != nil{
 		switch {
 		case errors.Is(err, repository.ErrNotFound):
 			protocol.WriteJSONError(w, http.StatusNotFound, "employee not found")
 		default:
-			h.logger.Errorw("failed to get employee", "error", err, "id", id)
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to get employee", "error", err, "id", id)
 			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
 		}
 		return
 	}
 
-	protocol.WriteJSON(w, http.StatusOK, employee)
+	if h.notModified(w, r, []employeeEntity.Employee{*employee}, nil) {
+		return
+	}
+	protocol.Write(w, r, http.StatusOK, employee)
+}
+
+// getFields reads only the columns of fields, through the list query as
+// GetById always reads every column.
+func (h *HttpHandler) getFields(w http.ResponseWriter, r *http.Request, id int64, fields []string) {
+	if id <= 0 {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	employees, err := h.employeeService.GetAll(r.Context(), employeeEntity.ListFilter{IDs: []int64{id}, Fields: withTimestamps(fields)})
+	if err != nil {
+		logging.FromContext(r.Context(), h.logger).Errorw("failed to get employee", "error", err, "id", id)
+		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+	if len(employees) == 0 {
+		protocol.WriteJSONError(w, http.StatusNotFound, "employee not found")
+		return
+	}
+
+	if h.notModified(w, r, employees, fields) {
+		return
+	}
+	writeEmployee(w, r, http.StatusOK, &employees[0], fields)
 }
 
-// CreateEmployee godoc
-// @Summary Create new employee
-// @Description Create a new employeee
-// @Tags employees
-// @Accept json
-// @Produce json
-// @Param employee body employeeEntity.Employee true "Employee data"
-// @Success 201 {object} employeeEntity.Employee
-// @Failure 500 {object} map[string]string	"Internal server error"
-// @Failure 404 {object} map[string]string	"not found"
-// @Router /employees [post]
 func (h *HttpHandler) Create(w http.ResponseWriter, r *http.Request){
 	ctx := r.Context()
 
 	var emp employeeEntity.Employee
-	if err := json.NewDecoder(r.Body).Decode(&emp); err != nil {
+	if err := protocol.Decode(r, &emp); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedMediaType) {
+			protocol.WriteJSONError(w, http.StatusUnsupportedMediaType, "unsupported media type")
+			return
+		}
 		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request bdoy")
 		return
 	}
@@ -116,28 +160,18 @@ func (h *HttpHandler) Create(w http.ResponseWriter, r *http.Request){
 			protocol.WriteJSONError(w, http.StatusConflict, "email cannot be null")
 		case errors.Is(err, repository.ErrNullOrNegSalary):
 			protocol.WriteJSONError(w, http.StatusConflict, "salary cannot be null or negative")
+		case errors.Is(err, repository.ErrManagerNotFound):
+			protocol.WriteJSONError(w, http.StatusBadRequest, "manager does not exist")
 		default:
-			h.logger.Errorw("failed to createa", "error", err)
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to createa", "error", err)
 			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
 		}
 		return
 	}
 
-	protocol.WriteJSON(w, http.StatusOK, emp)
+	writeEmployee(w, r, http.StatusOK, &emp, h.visibleFields(r))
 }
 
-// UpdateEmployee godoc
-// @Summary Update employee
-// @Description Update an employee
-// @Tags employees
-// @Accept json
-// @Produce json
-// @Param employeeId path int true "Employee ID"
-// @Param employee body employeeEntity.Employee true "Employee data"
-// @Success 200 {object} employeeEntity.Employee
-// @Failure 404 {object} map[string]string	"not found"
-// @Failure 500 {object} map[string]string	"Internal server error"
-// @Router /employees/{employeeId} [put]
 func (h *HttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -149,7 +183,11 @@ func (h *HttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var emp employeeEntity.Employee
-	if err := json.NewDecoder(r.Body).Decode(&emp); err != nil {
+	if err := protocol.Decode(r, &emp); err != nil {
+		if errors.Is(err, protocol.ErrUnsupportedMediaType) {
+			protocol.WriteJSONError(w, http.StatusUnsupportedMediaType, "unsupported media type")
+			return
+		}
 		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
@@ -164,27 +202,18 @@ func (h *HttpHandler) Update(w http.ResponseWriter, r *http.Request) {
 			protocol.WriteJSONError(w, http.StatusConflict, "email already exists")
 		case errors.Is(err, repository.ErrNullOrNegSalary):
 			protocol.WriteJSONError(w, http.StatusConflict, "salary cannot be null or negative")
+		case errors.Is(err, repository.ErrManagerNotFound):
+			protocol.WriteJSONError(w, http.StatusBadRequest, "manager does not exist")
 		default:
-			h.logger.Errorw("failed to update", "error", err, "id", id)
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to update", "error", err, "id", id)
 			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
 		}
 		return
 	}
 
-	protocol.WriteJSON(w, http.StatusOK, emp)
+	writeEmployee(w, r, http.StatusOK, &emp, h.visibleFields(r))
 }
 
-// DeleteEmnployee godoc
-// @Summary delete employee
-// @Description delete an empkloye
-// @Tags employees
-// @Accept json
-// @Produce json
-// @Param employeeId path int true "Employee ID"
-// @Success 200 {object} employeeEntity.Employee
-// @Failure 404 {object} map[string]string	"not found"
-// @Failure 500 {object} map[string]string	"Internal server error"
-// @Router /employees/{employeeId} [delete]
 func (h *HttpHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -200,7 +229,7 @@ func (h *HttpHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, repository.ErrNotFound):
 			protocol.WriteJSONError(w, http.StatusNotFound, "employee not found")
 		default:
-			h.logger.Errorw("failed to delete", "error", err, "id", id)
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to delete", "error", err, "id", id)
 			protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
 		}
 		return
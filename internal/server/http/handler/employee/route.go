@@ -1,18 +1,26 @@
 package employeeHandler
 
 import (
+	"time"
+
 	"github.com/go-chi/chi/v5"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/events"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
 	"go.uber.org/zap"
 )
 
 func RegisterRoute(
 	employeService service.EmployeesService,
+	feed *events.Feed,
+	heartbeat time.Duration,
+	cfg config.EmployeesConfig,
 	logger *zap.SugaredLogger,
 ) func(chi.Router){
 	return func(r chi.Router){
-		handler := newHttpHandler(employeService, logger)
+		handler := newHttpHandler(employeService, feed, heartbeat, cfg, logger)
 		r.Get("/", handler.GetAll)
+		r.Get("/events", handler.Events)
 		r.Get("/{employeeId}", handler.GetById)
 		r.Post("/", handler.Create)
 		r.Put("/{employeeId}", handler.Update)
@@ -0,0 +1,182 @@
+package graphqlHandler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"go.uber.org/zap"
+)
+
+// maxRequestBytes bounds the request body, queries are small.
+const maxRequestBytes = 1 << 20
+
+type HttpHandler struct {
+	schema   graphql.Schema
+	resolver *resolver
+	cfg      config.GraphQLConfig
+	logger   *zap.SugaredLogger
+}
+
+func newHttpHandler(employeeService service.EmployeesService, cfg config.GraphQLConfig, fields config.EmployeesConfig, logger *zap.SugaredLogger) (*HttpHandler, error) {
+	res := &resolver{employeeService: employeeService, fields: fields, logger: logger}
+	schema, err := res.schema()
+	if err != nil {
+		return nil, err
+	}
+
+	return &HttpHandler{
+		schema:   schema,
+		resolver: res,
+		cfg:      cfg,
+		logger:   logger,
+	}, nil
+}
+
+type request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Query runs a GraphQL query or mutation. It takes a JSON body on POST, or
+// query, operationName and variables parameters on GET, which is limited to
+// queries. Mutations require a token, auth.Identify in front of the route
+// checks it. Well-formed requests always get a 200 with data and errors.
+func (h *HttpHandler) Query(w http.ResponseWriter, r *http.Request) {
+	var req request
+	switch r.Method {
+	case http.MethodGet:
+		q := r.URL.Query()
+		req.Query = q.Get("query")
+		req.OperationName = q.Get("operationName")
+		if vars := q.Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				protocol.WriteJSONError(w, http.StatusBadRequest, "variables must be a JSON object")
+				return
+			}
+		}
+	default:
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if req.Query == "" {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "query is required")
+		return
+	}
+
+	// Errors in the query itself are GraphQL results, not HTTP errors.
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		protocol.WriteJSON(w, http.StatusOK, &graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if result := graphql.ValidateDocument(&h.schema, doc, nil); !result.IsValid {
+		protocol.WriteJSON(w, http.StatusOK, &graphql.Result{Errors: result.Errors})
+		return
+	}
+	if err := h.checkLimits(doc, req); err != nil {
+		protocol.WriteJSON(w, http.StatusOK, &graphql.Result{Errors: []gqlerrors.FormattedError{*err}})
+		return
+	}
+	if isMutation(doc, req.OperationName) {
+		if r.Method == http.MethodGet {
+			w.Header().Set("Allow", http.MethodPost)
+			protocol.WriteJSONError(w, http.StatusMethodNotAllowed, "mutations must be sent with POST")
+			return
+		}
+		if auth.Principal(r.Context()) == "" {
+			auth.Unauthorized(w)
+			return
+		}
+	}
+
+	ctx := context.WithValue(r.Context(), loadersKey{}, h.resolver.newLoaders())
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+
+	protocol.WriteJSON(w, http.StatusOK, result)
+}
+
+func (h *HttpHandler) checkLimits(doc *ast.Document, req request) *gqlerrors.FormattedError {
+	limitError := func(msg string) *gqlerrors.FormattedError {
+		return &gqlerrors.FormattedError{
+			Message:    msg,
+			Extensions: map[string]any{"code": "QUERY_TOO_COMPLEX"},
+		}
+	}
+
+	c, err := measure(doc, req.OperationName, req.Variables)
+	if err != nil {
+		e := gqlerrors.NewFormattedError(err.Error())
+		return &e
+	}
+	if c.depth > h.cfg.MaxDepth {
+		return limitError(fmt.Sprintf("query depth %d exceeds the limit of %d", c.depth, h.cfg.MaxDepth))
+	}
+	if c.complexity > h.cfg.MaxComplexity {
+		return limitError(fmt.Sprintf("query complexity %d exceeds the limit of %d", c.complexity, h.cfg.MaxComplexity))
+	}
+	return nil
+}
+
+func isMutation(doc *ast.Document, operationName string) bool {
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*ast.OperationDefinition); ok {
+			if operationName == "" || (op.Name != nil && op.Name.Value == operationName) {
+				return op.Operation == ast.OperationTypeMutation
+			}
+		}
+	}
+	return false
+}
+
+// GraphiQL serves the in-browser GraphQL IDE for /graphql.
+func (h *HttpHandler) GraphiQL(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(graphiqlPage))
+}
+
+const graphiqlPage = `<!doctype html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>GraphiQL</title>
+  <style>body { margin: 0; } #graphiql { height: 100vh; }</style>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+</head>
+<body>
+  <div id="graphiql">Loading...</div>
+  <script>
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(
+      React.createElement(GraphiQL, {
+        fetcher: GraphiQL.createFetcher({ url: "/graphql" }),
+        defaultQuery: "{\n  employees(first: 10) {\n    edges { node { id name position manager { name } } }\n    pageInfo { hasNextPage endCursor }\n  }\n}\n",
+      })
+    );
+  </script>
+</body>
+</html>
+`
@@ -0,0 +1,115 @@
+package graphqlHandler
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// cost is what a query asks for: how deeply its fields nest and how many
+// of them it resolves.
+type cost struct {
+	depth      int
+	complexity int
+}
+
+// measure walks the selected operation of a validated document. Every field
+// counts once, the selections below a paginated field count once per item
+// it may return. Introspection fields are free so GraphiQL keeps working.
+func measure(doc *ast.Document, operationName string, variables map[string]any) (cost, error) {
+	var op *ast.OperationDefinition
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *ast.OperationDefinition:
+			if operationName == "" || (def.Name != nil && def.Name.Value == operationName) {
+				op = def
+			}
+		case *ast.FragmentDefinition:
+			fragments[def.Name.Value] = def
+		}
+	}
+	if op == nil {
+		return cost{}, fmt.Errorf("unknown operation %q", operationName)
+	}
+
+	// Variables left out take their default, like they do when the
+	// query runs.
+	vars := map[string]any{}
+	for _, def := range op.VariableDefinitions {
+		if value, ok := def.DefaultValue.(*ast.IntValue); ok {
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				vars[def.Variable.Name.Value] = float64(n)
+			}
+		}
+	}
+	maps.Copy(vars, variables)
+
+	m := meter{fragments: fragments, variables: vars}
+	complexity, depth := m.selectionSet(op.SelectionSet, 0)
+	return cost{depth: depth, complexity: complexity}, nil
+}
+
+type meter struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]any
+}
+
+// selectionSet returns the complexity of set and the deepest level reached
+// below depth. Validation already rejected fragment cycles.
+func (m meter) selectionSet(set *ast.SelectionSet, depth int) (complexity, maxDepth int) {
+	if set == nil {
+		return 0, depth
+	}
+
+	maxDepth = depth
+	for _, sel := range set.Selections {
+		var c, d int
+		switch sel := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(sel.Name.Value, "__") {
+				continue
+			}
+			c, d = m.selectionSet(sel.SelectionSet, depth+1)
+			c = 1 + c*m.items(sel)
+		case *ast.InlineFragment:
+			c, d = m.selectionSet(sel.SelectionSet, depth)
+		case *ast.FragmentSpread:
+			if frag, ok := m.fragments[sel.Name.Value]; ok {
+				c, d = m.selectionSet(frag.SelectionSet, depth)
+			}
+		}
+		complexity += c
+		maxDepth = max(maxDepth, d)
+	}
+	return complexity, maxDepth
+}
+
+// items is how many results a field may return: its page size for
+// paginated fields, one otherwise.
+func (m meter) items(field *ast.Field) int {
+	if !paginated[field.Name.Value] {
+		return 1
+	}
+
+	first := defaultPageSize
+	for _, arg := range field.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch value := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil {
+				first = n
+			}
+		case *ast.Variable:
+			if n, ok := m.variables[value.Name.Value].(float64); ok {
+				first = int(n)
+			}
+		}
+	}
+	return min(max(first, 1), maxPageSize)
+}
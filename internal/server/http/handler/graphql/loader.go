@@ -0,0 +1,70 @@
+package graphqlHandler
+
+import (
+	"context"
+	"sync"
+)
+
+// Loader batches the lookups of one request. Load only records the key and
+// returns a thunk; the executor calls thunks after resolving a whole level
+// of the query, so the first one fetches every key recorded by then in one
+// call. Results are cached for the rest of the request.
+type Loader[K comparable, V any] struct {
+	fetch func(context.Context, []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loaded[V]
+}
+
+type loaded[V any] struct {
+	value V
+	found bool
+	err   error
+	done  bool
+}
+
+// NewLoader returns a loader calling fetch with the pending keys. Keys
+// missing from the map fetch returns load as not found.
+func NewLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch:   fetch,
+		results: map[K]*loaded[V]{},
+	}
+}
+
+// Load returns a thunk resolving to the value for key and whether it was
+// found.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) func() (V, bool, error) {
+	l.mu.Lock()
+	if _, ok := l.results[key]; !ok {
+		l.results[key] = &loaded[V]{}
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		res := l.results[key]
+		if !res.done {
+			l.dispatch(ctx)
+		}
+		return res.value, res.found, res.err
+	}
+}
+
+// dispatch must be called with mu held.
+func (l *Loader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		res := l.results[key]
+		res.value, res.found = values[key]
+		res.err = err
+		res.done = true
+	}
+}
@@ -0,0 +1,32 @@
+package graphqlHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// RegisterRoute mounts /graphql, and the GraphiQL page at /graphiql when
+// cfg.GraphiQL is set. It fails when the schema does not build. Employee fields in fields'
+// RestrictedFields resolve to an error for callers without a privileged
+// role, which auth.Identify in front of the routes provides.
+func RegisterRoute(
+	employeeService service.EmployeesService,
+	cfg config.GraphQLConfig,
+	fields config.EmployeesConfig,
+	logger *zap.SugaredLogger,
+) (func(chi.Router), error) {
+	handler, err := newHttpHandler(employeeService, cfg, fields, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(r chi.Router) {
+		r.Get("/graphql", handler.Query)
+		r.Post("/graphql", handler.Query)
+		if cfg.GraphiQL {
+			r.Get("/graphiql", handler.GraphiQL)
+		}
+	}, nil
+}
@@ -0,0 +1,491 @@
+package graphqlHandler
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+// paginated lists the connection fields, their selections are counted once
+// per requested item by the complexity limit.
+var paginated = map[string]bool{"employees": true}
+
+type resolver struct {
+	employeeService service.EmployeesService
+	fields          config.EmployeesConfig
+	logger          *zap.SugaredLogger
+}
+
+// loaders are created for every request, so batches and their cache never
+// outlive it.
+type loaders struct {
+	employees *Loader[int64, employeeEntity.Employee]
+}
+
+type loadersKey struct{}
+
+func (res *resolver) newLoaders() *loaders {
+	return &loaders{
+		employees: NewLoader(func(ctx context.Context, ids []int64) (map[int64]employeeEntity.Employee, error) {
+			emps, err := res.employeeService.GetAll(ctx, employeeEntity.ListFilter{IDs: ids})
+			if err != nil {
+				return nil, err
+			}
+			byID := make(map[int64]employeeEntity.Employee, len(emps))
+			for _, emp := range emps {
+				byID[emp.ID] = emp
+			}
+			return byID, nil
+		}),
+	}
+}
+
+func loadersFrom(ctx context.Context) *loaders {
+	return ctx.Value(loadersKey{}).(*loaders)
+}
+
+type connection struct {
+	edges       []edge
+	hasNextPage bool
+}
+
+type edge struct {
+	cursor string
+	node   employeeEntity.Employee
+}
+
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:        "JSON",
+	Description: "Any JSON value, used for custom attributes.",
+	Serialize:   func(value any) any { return value },
+	ParseValue:  func(value any) any { return value },
+	ParseLiteral: func(value ast.Value) any {
+		return literal(value)
+	},
+})
+
+func literal(value ast.Value) any {
+	switch value := value.(type) {
+	case *ast.StringValue:
+		return value.Value
+	case *ast.BooleanValue:
+		return value.Value
+	case *ast.IntValue:
+		// Numbers decode to float64 like in JSON request bodies, which is
+		// what attribute validation expects.
+		f, _ := strconv.ParseFloat(value.Value, 64)
+		return f
+	case *ast.FloatValue:
+		f, _ := strconv.ParseFloat(value.Value, 64)
+		return f
+	case *ast.ListValue:
+		list := make([]any, 0, len(value.Values))
+		for _, v := range value.Values {
+			list = append(list, literal(v))
+		}
+		return list
+	case *ast.ObjectValue:
+		obj := make(map[string]any, len(value.Fields))
+		for _, field := range value.Fields {
+			obj[field.Name.Value] = literal(field.Value)
+		}
+		return obj
+	}
+	return nil
+}
+
+func (res *resolver) schema() (graphql.Schema, error) {
+	employeeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Employee",
+		Fields: graphql.Fields{
+			"id":         res.employeeField("id", graphql.NewNonNull(graphql.ID), func(e employeeEntity.Employee) any { return strconv.FormatInt(e.ID, 10) }),
+			"name":       res.employeeField("name", graphql.NewNonNull(graphql.String), func(e employeeEntity.Employee) any { return e.Name }),
+			"email":      res.employeeField("email", graphql.NewNonNull(graphql.String), func(e employeeEntity.Employee) any { return e.Email }),
+			"position":   res.employeeField("position", graphql.NewNonNull(graphql.String), func(e employeeEntity.Employee) any { return e.Position }),
+			"salary":     res.employeeField("salary", graphql.NewNonNull(graphql.Float), func(e employeeEntity.Employee) any { return e.Salary }),
+			"attributes": res.employeeField("attributes", jsonScalar, func(e employeeEntity.Employee) any { return e.Attributes }),
+			"createdAt":  res.employeeField("created_at", graphql.NewNonNull(graphql.DateTime), func(e employeeEntity.Employee) any { return e.CreatedAt }),
+			"updatedAt":  res.employeeField("updated_at", graphql.NewNonNull(graphql.DateTime), func(e employeeEntity.Employee) any { return e.UpdatedAt }),
+			"managerId": res.employeeField("manager_id", graphql.ID, func(e employeeEntity.Employee) any {
+				if e.ManagerID == nil {
+					return nil
+				}
+				return strconv.FormatInt(*e.ManagerID, 10)
+			}),
+		},
+	})
+	employeeType.AddFieldConfig("manager", &graphql.Field{
+		Type:        employeeType,
+		Description: "Loaded in one batch for every employee of a page.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			emp := p.Source.(employeeEntity.Employee)
+			if !res.visible(p.Context, "manager_id") {
+				return nil, employeeEntity.RestrictedFieldError("manager_id")
+			}
+			if emp.ManagerID == nil {
+				return nil, nil
+			}
+			load := loadersFrom(p.Context).employees.Load(p.Context, *emp.ManagerID)
+			return func() (any, error) {
+				manager, ok, err := load()
+				if err != nil {
+					return nil, res.resolverError(p.Context, err, "failed to load manager", "id", emp.ID)
+				}
+				if !ok {
+					return nil, nil
+				}
+				return manager, nil
+			}, nil
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "EmployeeEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(edge).cursor, nil },
+			},
+			"node": &graphql.Field{
+				Type:    graphql.NewNonNull(employeeType),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(edge).node, nil },
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source.(connection).hasNextPage, nil },
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					conn := p.Source.(connection)
+					if len(conn.edges) == 0 {
+						return nil, nil
+					}
+					return conn.edges[len(conn.edges)-1].cursor, nil
+				},
+			},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "EmployeeConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(connection).edges, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type:    graphql.NewNonNull(pageInfoType),
+				Resolve: func(p graphql.ResolveParams) (any, error) { return p.Source, nil },
+			},
+		},
+	})
+
+	attributeFilterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "AttributeFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"value": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	employeeInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "EmployeeInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"name":       &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"email":      &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"position":   &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"salary":     &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.Float)},
+			"managerId":  &graphql.InputObjectFieldConfig{Type: graphql.ID},
+			"attributes": &graphql.InputObjectFieldConfig{Type: jsonScalar},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"employee": &graphql.Field{
+				Type: employeeType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: res.employee,
+			},
+			"employees": &graphql.Field{
+				Type:        graphql.NewNonNull(connectionType),
+				Description: "Employees ordered by id, first defaults to 50 and is capped at 500.",
+				Args: graphql.FieldConfigArgument{
+					"first":      &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":      &graphql.ArgumentConfig{Type: graphql.String},
+					"attributes": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(attributeFilterType))},
+				},
+				Resolve: res.employees,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createEmployee": &graphql.Field{
+				Type: graphql.NewNonNull(employeeType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(employeeInputType)},
+				},
+				Resolve: res.createEmployee,
+			},
+			"updateEmployee": &graphql.Field{
+				Type:        graphql.NewNonNull(employeeType),
+				Description: "Replaces every field of the employee.",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(employeeInputType)},
+				},
+				Resolve: res.updateEmployee,
+			},
+			"deleteEmployee": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.ID),
+				Description: "Returns the id of the deleted employee.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: res.deleteEmployee,
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+// employeeField resolves the employee field named field in the REST API
+// with get, or fails when the caller's role may not read it.
+func (res *resolver) employeeField(field string, typ graphql.Output, get func(employeeEntity.Employee) any) *graphql.Field {
+	return &graphql.Field{
+		Type: typ,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			if !res.visible(p.Context, field) {
+				return nil, employeeEntity.RestrictedFieldError(field)
+			}
+			return get(p.Source.(employeeEntity.Employee)), nil
+		},
+	}
+}
+
+// visible reports whether the caller may read the employee field.
+func (res *resolver) visible(ctx context.Context, field string) bool {
+	visible := res.fields.VisibleFields(auth.Role(ctx))
+	return visible == nil || slices.Contains(visible, field)
+}
+
+func (res *resolver) employee(p graphql.ResolveParams) (any, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	emp, err := res.employeeService.GetById(p.Context, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, res.resolverError(p.Context, err, "failed to get employee", "id", id)
+	}
+	return *emp, nil
+}
+
+func (res *resolver) employees(p graphql.ResolveParams) (any, error) {
+	first, _ := p.Args["first"].(int)
+	if first < 0 {
+		return nil, badInput("first cannot be negative")
+	}
+	first = min(first, maxPageSize)
+
+	filter := employeeEntity.ListFilter{Limit: first + 1}
+	if after, ok := p.Args["after"].(string); ok {
+		id, err := decodeCursor(after)
+		if err != nil {
+			return nil, badInput("invalid cursor")
+		}
+		filter.AfterID = id
+	}
+	if attrs, ok := p.Args["attributes"].([]any); ok && len(attrs) > 0 {
+		filter.Attributes = make(map[string]any, len(attrs))
+		for _, attr := range attrs {
+			attr := attr.(map[string]any)
+			filter.Attributes[attr["name"].(string)] = attr["value"]
+		}
+	}
+
+	conn := connection{}
+	if first == 0 {
+		return conn, nil
+	}
+
+	// One employee more than asked for tells whether there is a next page.
+	emps, err := res.employeeService.GetAll(p.Context, filter)
+	if err != nil {
+		return nil, res.resolverError(p.Context, err, "failed to list employees")
+	}
+	if len(emps) > first {
+		emps = emps[:first]
+		conn.hasNextPage = true
+	}
+	for _, emp := range emps {
+		conn.edges = append(conn.edges, edge{cursor: encodeCursor(emp.ID), node: emp})
+	}
+	return conn, nil
+}
+
+func (res *resolver) createEmployee(p graphql.ResolveParams) (any, error) {
+	emp, err := employeeFromInput(p.Args["input"].(map[string]any))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := res.employeeService.Create(p.Context, emp); err != nil {
+		return nil, res.resolverError(p.Context, err, "failed to create employee")
+	}
+	return *emp, nil
+}
+
+func (res *resolver) updateEmployee(p graphql.ResolveParams) (any, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+	emp, err := employeeFromInput(p.Args["input"].(map[string]any))
+	if err != nil {
+		return nil, err
+	}
+	emp.ID = id
+
+	if err := res.employeeService.Update(p.Context, emp); err != nil {
+		return nil, res.resolverError(p.Context, err, "failed to update employee", "id", id)
+	}
+	return *emp, nil
+}
+
+func (res *resolver) deleteEmployee(p graphql.ResolveParams) (any, error) {
+	id, err := parseID(p.Args["id"])
+	if err != nil {
+		return nil, err
+	}
+
+	if err := res.employeeService.Delete(p.Context, id); err != nil {
+		return nil, res.resolverError(p.Context, err, "failed to delete employee", "id", id)
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+func employeeFromInput(input map[string]any) (*employeeEntity.Employee, error) {
+	emp := &employeeEntity.Employee{}
+	emp.Name, _ = input["name"].(string)
+	emp.Email, _ = input["email"].(string)
+	emp.Position, _ = input["position"].(string)
+	emp.Salary, _ = input["salary"].(float64)
+	if managerID, ok := input["managerId"]; ok && managerID != nil {
+		id, err := parseID(managerID)
+		if err != nil {
+			return nil, err
+		}
+		emp.ManagerID = &id
+	}
+	if attrs, ok := input["attributes"]; ok && attrs != nil {
+		obj, ok := attrs.(map[string]any)
+		if !ok {
+			return nil, badInput("attributes must be an object")
+		}
+		emp.Attributes = obj
+	}
+	return emp, nil
+}
+
+func parseID(value any) (int64, error) {
+	s, _ := value.(string)
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, badInput("invalid employee id")
+	}
+	return id, nil
+}
+
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return id, nil
+}
+
+// resolverError is the GraphQL counterpart of the REST handlers'
+// writeError: callers see what they did wrong, anything else is logged.
+func (res *resolver) resolverError(ctx context.Context, err error, msg string, keysAndValues ...any) error {
+	var invalidEmployee employeeEntity.ValidationError
+	var invalidAttributes *attributeEntity.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		return &queryError{msg: "not found", code: "NOT_FOUND"}
+	case errors.Is(err, repository.ErrUniqueViolation):
+		return &queryError{msg: err.Error(), code: "CONFLICT"}
+	case errors.Is(err, repository.ErrNullEmail),
+		errors.Is(err, repository.ErrNullOrNegSalary),
+		errors.Is(err, repository.ErrManagerNotFound),
+		errors.As(err, &invalidEmployee),
+		errors.As(err, &invalidAttributes):
+		return badInput(err.Error())
+	default:
+		logging.FromContext(ctx, res.logger).Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		return &queryError{msg: "internal server error", code: "INTERNAL_SERVER_ERROR"}
+	}
+}
+
+// queryError carries a code in the error's extensions, so clients do not
+// have to match messages.
+type queryError struct {
+	msg  string
+	code string
+}
+
+func badInput(msg string) error {
+	return &queryError{msg: msg, code: "BAD_USER_INPUT"}
+}
+
+func (e *queryError) Error() string { return e.msg }
+
+func (e *queryError) Extensions() map[string]any {
+	return map[string]any{"code": e.code}
+}
@@ -0,0 +1,225 @@
+package leaveHandler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	leaveEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/leaves"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/auth"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+type HttpHandler struct {
+	leaveService service.LeaveService
+	// employees maps principal names to the employee they approve as.
+	employees map[string]int64
+	logger    *zap.SugaredLogger
+}
+
+func newHttpHandler(leaveService service.LeaveService, employees map[string]int64, logger *zap.SugaredLogger) *HttpHandler {
+	return &HttpHandler{
+		leaveService: leaveService,
+		employees:    employees,
+		logger:       logger,
+	}
+}
+
+type createLeaveRequestPayload struct {
+	LeaveTypeID int64  `json:"leave_type_id" example:"1"`
+	StartDate   string `json:"start_date" example:"2026-03-02"`
+	EndDate     string `json:"end_date" example:"2026-03-06"`
+	Reason      string `json:"reason" example:"Family holiday"`
+}
+
+func (h *HttpHandler) GetTypes(w http.ResponseWriter, r *http.Request) {
+	types, err := h.leaveService.GetTypes(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context(), h.logger).Errorw("failed to get leave types", "error", err)
+		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, types)
+}
+
+func (h *HttpHandler) CreateType(w http.ResponseWriter, r *http.Request) {
+	var lt leaveEntity.LeaveType
+	if err := json.NewDecoder(r.Body).Decode(&lt); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := h.leaveService.CreateType(r.Context(), &lt); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrLeaveTypeExists):
+			protocol.WriteJSONError(w, http.StatusConflict, "leave type already exists")
+		default:
+			logging.FromContext(r.Context(), h.logger).Errorw("failed to create leave type", "error", err)
+			protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+		}
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, lt)
+}
+
+func (h *HttpHandler) GetByEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	requests, err := h.leaveService.GetRequestsByEmployee(r.Context(), employeeID)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get leave requests", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, requests)
+}
+
+func (h *HttpHandler) Create(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	var payload createLeaveRequestPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	start, err := time.Parse(dateLayout, payload.StartDate)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "start_date must be formatted as YYYY-MM-DD")
+		return
+	}
+	end, err := time.Parse(dateLayout, payload.EndDate)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "end_date must be formatted as YYYY-MM-DD")
+		return
+	}
+
+	req := leaveEntity.LeaveRequest{
+		EmployeeID:  employeeID,
+		LeaveTypeID: payload.LeaveTypeID,
+		StartDate:   start,
+		EndDate:     end,
+		Reason:      payload.Reason,
+	}
+	if err := h.leaveService.CreateRequest(r.Context(), &req); err != nil {
+		h.writeError(w, r, err, "failed to create leave request", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, req)
+}
+
+func (h *HttpHandler) GetBalances(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	var year int
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		year, err = strconv.Atoi(yearStr)
+		if err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "invalid year")
+			return
+		}
+	}
+
+	balances, err := h.leaveService.GetBalances(r.Context(), employeeID, year)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get leave balances", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, balances)
+}
+
+func (h *HttpHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "requestId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid leave request id")
+		return
+	}
+
+	req, err := h.leaveService.GetRequestById(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get leave request", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, req)
+}
+
+func (h *HttpHandler) Approve(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.leaveService.Approve)
+}
+
+func (h *HttpHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.leaveService.Reject)
+}
+
+func (h *HttpHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	fn func(context.Context, int64, int64) (*leaveEntity.LeaveRequest, error),
+) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "requestId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid leave request id")
+		return
+	}
+
+	// The approver is whoever the token belongs to, never what the caller
+	// claims to be.
+	approverID, ok := h.employees[auth.Principal(r.Context())]
+	if !ok {
+		protocol.WriteJSONError(w, http.StatusForbidden, "caller is not linked to an employee")
+		return
+	}
+
+	req, err := fn(r.Context(), id, approverID)
+	if err != nil {
+		h.writeError(w, r, err, "failed to decide leave request", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, req)
+}
+
+func (h *HttpHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string, keysAndValues ...any) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		protocol.WriteJSONError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, repository.ErrLeaveTypeNotFound):
+		protocol.WriteJSONError(w, http.StatusBadRequest, "leave type does not exist")
+	case errors.Is(err, repository.ErrNotManager):
+		protocol.WriteJSONError(w, http.StatusForbidden, err.Error())
+	case errors.Is(err, repository.ErrLeaveOverlap),
+		errors.Is(err, repository.ErrInsufficientBalance),
+		errors.Is(err, repository.ErrLeaveNotPending):
+		protocol.WriteJSONError(w, http.StatusConflict, err.Error())
+	default:
+		logging.FromContext(r.Context(), h.logger).Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
@@ -0,0 +1,29 @@
+package leaveHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// RegisterRoute registers absolute paths because leave requests hang off
+// /api/v1/employees/{employeeId}, which is already mounted by the employee
+// handler. Approvals are made as the employee employees maps the
+// authenticated caller to.
+func RegisterRoute(
+	leaveService service.LeaveService,
+	employees map[string]int64,
+	logger *zap.SugaredLogger,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(leaveService, employees, logger)
+		r.Get("/api/v1/leave-types", handler.GetTypes)
+		r.Post("/api/v1/leave-types", handler.CreateType)
+		r.Get("/api/v1/leave-requests/{requestId}", handler.GetById)
+		r.Post("/api/v1/leave-requests/{requestId}/approve", handler.Approve)
+		r.Post("/api/v1/leave-requests/{requestId}/reject", handler.Reject)
+		r.Get("/api/v1/employees/{employeeId}/leave-requests", handler.GetByEmployee)
+		r.Post("/api/v1/employees/{employeeId}/leave-requests", handler.Create)
+		r.Get("/api/v1/employees/{employeeId}/leave-balances", handler.GetBalances)
+	}
+}
@@ -0,0 +1,182 @@
+package timeEntryHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	timeEntryEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/timeentries"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+const dateLayout = "2006-01-02"
+
+type HttpHandler struct {
+	timeEntryService service.TimeEntryService
+	logger           *zap.SugaredLogger
+}
+
+func newHttpHandler(timeEntryService service.TimeEntryService, logger *zap.SugaredLogger) *HttpHandler {
+	return &HttpHandler{
+		timeEntryService: timeEntryService,
+		logger:           logger,
+	}
+}
+
+type clockPayload struct {
+	Note string `json:"note" example:"Working from home"`
+}
+
+type adjustPayload struct {
+	ClockIn  time.Time  `json:"clock_in" example:"2026-03-02T09:00:00Z"`
+	ClockOut *time.Time `json:"clock_out" example:"2026-03-02T17:30:00Z"`
+	Note     string     `json:"note" example:"Forgot to clock out"`
+}
+
+func (h *HttpHandler) GetByEmployee(w http.ResponseWriter, r *http.Request) {
+	employeeID, from, to, ok := parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.timeEntryService.GetByEmployee(r.Context(), employeeID, from, to)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get time entries", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, entries)
+}
+
+func (h *HttpHandler) Timesheet(w http.ResponseWriter, r *http.Request) {
+	employeeID, from, to, ok := parseRange(w, r)
+	if !ok {
+		return
+	}
+
+	sheet, err := h.timeEntryService.Timesheet(r.Context(), employeeID, from, to)
+	if err != nil {
+		h.writeError(w, r, err, "failed to build timesheet", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, sheet)
+}
+
+func (h *HttpHandler) Start(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	var payload clockPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil && !errors.Is(err, io.EOF) {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	entry, err := h.timeEntryService.Start(r.Context(), employeeID, payload.Note)
+	if err != nil {
+		h.writeError(w, r, err, "failed to clock in", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, entry)
+}
+
+func (h *HttpHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return
+	}
+
+	entry, err := h.timeEntryService.Stop(r.Context(), employeeID)
+	if err != nil {
+		h.writeError(w, r, err, "failed to clock out", "employee_id", employeeID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, entry)
+}
+
+func (h *HttpHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "entryId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid time entry id")
+		return
+	}
+
+	var payload adjustPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	entry := timeEntryEntity.TimeEntry{
+		ID:       id,
+		ClockIn:  payload.ClockIn,
+		ClockOut: payload.ClockOut,
+		Note:     payload.Note,
+	}
+	if err := h.timeEntryService.Update(r.Context(), &entry); err != nil {
+		h.writeError(w, r, err, "failed to adjust time entry", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, entry)
+}
+
+// parseRange reads the employee id and the from/to dates, defaulting to the
+// Monday to Sunday week containing today.
+func parseRange(w http.ResponseWriter, r *http.Request) (int64, time.Time, time.Time, bool) {
+	employeeID, err := strconv.ParseInt(chi.URLParam(r, "employeeId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid employee id")
+		return 0, time.Time{}, time.Time{}, false
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day()-(int(now.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 6)
+
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(dateLayout, v); err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "from must be formatted as YYYY-MM-DD")
+			return 0, time.Time{}, time.Time{}, false
+		}
+	}
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(dateLayout, v); err != nil {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "to must be formatted as YYYY-MM-DD")
+			return 0, time.Time{}, time.Time{}, false
+		}
+	}
+
+	return employeeID, from, to, true
+}
+
+func (h *HttpHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string, keysAndValues ...any) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		protocol.WriteJSONError(w, http.StatusNotFound, "not found")
+	case errors.Is(err, repository.ErrTimeEntryOverlap),
+		errors.Is(err, repository.ErrTimeEntryOpen),
+		errors.Is(err, repository.ErrNoOpenTimeEntry):
+		protocol.WriteJSONError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, repository.ErrTimeEntryLocked):
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.FromContext(r.Context(), h.logger).Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	}
+}
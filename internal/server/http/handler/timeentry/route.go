@@ -0,0 +1,22 @@
+package timeEntryHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+// RegisterRoute registers absolute paths, see leaveHandler.RegisterRoute.
+func RegisterRoute(
+	timeEntryService service.TimeEntryService,
+	logger *zap.SugaredLogger,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(timeEntryService, logger)
+		r.Get("/api/v1/employees/{employeeId}/time-entries", handler.GetByEmployee)
+		r.Post("/api/v1/employees/{employeeId}/time-entries/start", handler.Start)
+		r.Post("/api/v1/employees/{employeeId}/time-entries/stop", handler.Stop)
+		r.Get("/api/v1/employees/{employeeId}/timesheet", handler.Timesheet)
+		r.Put("/api/v1/time-entries/{entryId}", handler.Update)
+	}
+}
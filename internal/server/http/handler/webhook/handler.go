@@ -0,0 +1,205 @@
+package webhookHandler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+type HttpHandler struct {
+	webhookService service.WebhookService
+	logger         *zap.SugaredLogger
+}
+
+func newHttpHandler(webhookService service.WebhookService, logger *zap.SugaredLogger) *HttpHandler {
+	return &HttpHandler{
+		webhookService: webhookService,
+		logger:         logger,
+	}
+}
+
+type subscriptionPayload struct {
+	URL         string   `json:"url" example:"https://hooks.example.com/employees"`
+	Description string   `json:"description" example:"Payroll sync"`
+	Events      []string `json:"events" example:"employee.created,employee.deleted"`
+	// Active defaults to true.
+	Active *bool `json:"active" example:"true"`
+}
+
+func (p subscriptionPayload) subscription() webhookEntity.Subscription {
+	sub := webhookEntity.Subscription{
+		URL:         p.URL,
+		Description: p.Description,
+		Events:      p.Events,
+		Active:      true,
+	}
+	if p.Active != nil {
+		sub.Active = *p.Active
+	}
+	return sub
+}
+
+func (h *HttpHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.webhookService.GetAll(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context(), h.logger).Errorw("failed to get webhooks", "error", err)
+		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, subs)
+}
+
+func (h *HttpHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var payload subscriptionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sub := payload.subscription()
+	if err := h.webhookService.Create(r.Context(), &sub); err != nil {
+		h.writeError(w, r, err, "failed to create webhook")
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusCreated, sub)
+}
+
+func (h *HttpHandler) GetById(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	sub, err := h.webhookService.GetById(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get webhook", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, sub)
+}
+
+func (h *HttpHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var payload subscriptionPayload
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	sub := payload.subscription()
+	sub.ID = id
+	if err := h.webhookService.Update(r.Context(), &sub); err != nil {
+		h.writeError(w, r, err, "failed to update webhook", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, sub)
+}
+
+func (h *HttpHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	if err := h.webhookService.Delete(r.Context(), id); err != nil {
+		h.writeError(w, r, err, "failed to delete webhook", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, "deleted successfully")
+}
+
+func (h *HttpHandler) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	var limit int
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			protocol.WriteJSONError(w, http.StatusBadRequest, "limit must be a positive number")
+			return
+		}
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(r.Context(), id, limit)
+	if err != nil {
+		h.writeError(w, r, err, "failed to get webhook deliveries", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusOK, deliveries)
+}
+
+func (h *HttpHandler) SendTest(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+
+	delivery, err := h.webhookService.SendTest(r.Context(), id)
+	if err != nil {
+		h.writeError(w, r, err, "failed to send test webhook", "id", id)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+func (h *HttpHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "webhookId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid webhook id")
+		return
+	}
+	deliveryID, err := strconv.ParseInt(chi.URLParam(r, "deliveryId"), 10, 64)
+	if err != nil {
+		protocol.WriteJSONError(w, http.StatusBadRequest, "invalid delivery id")
+		return
+	}
+
+	delivery, err := h.webhookService.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		h.writeError(w, r, err, "failed to redeliver webhook", "id", id, "delivery_id", deliveryID)
+		return
+	}
+
+	protocol.WriteJSON(w, http.StatusAccepted, delivery)
+}
+
+func (h *HttpHandler) writeError(w http.ResponseWriter, r *http.Request, err error, msg string, keysAndValues ...any) {
+	var invalid *webhookEntity.ValidationError
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		protocol.WriteJSONError(w, http.StatusNotFound, "not found")
+	case errors.As(err, &invalid):
+		protocol.WriteJSONError(w, http.StatusBadRequest, err.Error())
+	default:
+		logging.FromContext(r.Context(), h.logger).Errorw(msg, append([]any{"error", err}, keysAndValues...)...)
+		protocol.WriteJSONError(w, http.StatusInternalServerError, "internal server error")
+	}
+}
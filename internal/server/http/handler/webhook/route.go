@@ -0,0 +1,24 @@
+package webhookHandler
+
+import (
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+)
+
+func RegisterRoute(
+	webhookService service.WebhookService,
+	logger *zap.SugaredLogger,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		handler := newHttpHandler(webhookService, logger)
+		r.Get("/", handler.GetAll)
+		r.Post("/", handler.Create)
+		r.Get("/{webhookId}", handler.GetById)
+		r.Put("/{webhookId}", handler.Update)
+		r.Delete("/{webhookId}", handler.Delete)
+		r.Get("/{webhookId}/deliveries", handler.GetDeliveries)
+		r.Post("/{webhookId}/test", handler.SendTest)
+		r.Post("/{webhookId}/deliveries/{deliveryId}/redeliver", handler.Redeliver)
+	}
+}
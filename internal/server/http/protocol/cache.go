@@ -0,0 +1,72 @@
+package protocol
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NotModified sets the caching headers of a read whose content last changed
+// at lastModified and is identified by the weak etag, and answers 304 when
+// the conditional headers of r show the client already has it. Handlers
+// call it before encoding anything and are done when it returns true.
+// A zero lastModified or empty etag leaves that validator out.
+//
+// Answers are private, they depend on who asks. maxAge 0 lets clients keep
+// them but revalidate every time.
+func NotModified(w http.ResponseWriter, r *http.Request, lastModified time.Time, etag string, maxAge time.Duration) bool {
+	header := w.Header()
+	if maxAge > 0 {
+		header.Set("Cache-Control", "private, max-age="+strconv.Itoa(int(maxAge.Seconds())))
+	} else {
+		header.Set("Cache-Control", "private, no-cache")
+	}
+	// Restricted fields make the same URL read differently per token.
+	header.Add("Vary", "Authorization")
+	if etag != "" {
+		etag = `W/"` + etag + `"`
+		header.Set("ETag", etag)
+	}
+	// HTTP dates have whole seconds, a change in the same second as the
+	// client's copy is only caught by the ETag.
+	lastModified = lastModified.UTC().Truncate(time.Second)
+	if !lastModified.IsZero() {
+		header.Set("Last-Modified", lastModified.Format(http.TimeFormat))
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		// If-None-Match overrides If-Modified-Since when both are sent.
+		if etag == "" || !matchesETag(match, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+		if err != nil || lastModified.IsZero() || lastModified.After(since) {
+			return false
+		}
+	}
+
+	header.Del("Content-Type")
+	header.Del("Content-Length")
+	// Write is never reached to add it, but the 304 varies like the 200.
+	header.Add("Vary", "Accept")
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matchesETag compares the If-None-Match list with etag, weakly as the
+// header requires.
+func matchesETag(list, etag string) bool {
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,245 @@
+package protocol
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"go.yaml.in/yaml/v3"
+)
+
+// ErrUnsupportedMediaType is returned by Decode for bodies in a format it
+// cannot read; handlers answer it with 415.
+var ErrUnsupportedMediaType = errors.New("unsupported media type")
+
+// Decode reads the request body into v according to its Content-Type: JSON,
+// which is also assumed when the header is missing, XML, YAML or
+// MessagePack. Field names are the json tags of v in every format.
+func Decode(r *http.Request, v any) error {
+	mediaType := "application/json"
+	if header := r.Header.Get("Content-Type"); header != "" {
+		parsed, _, err := mime.ParseMediaType(header)
+		if err != nil {
+			return ErrUnsupportedMediaType
+		}
+		mediaType = parsed
+	}
+
+	c := codecFor(mediaType)
+	if c == nil || c.decode == nil {
+		return ErrUnsupportedMediaType
+	}
+	return c.decode(r, v)
+}
+
+func decodeJSON(r *http.Request, v any) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
+
+// decodeYAML and decodeMsgpack read the body into plain values and hand
+// them to encoding/json, so the json tags and types of v apply unchanged.
+func decodeYAML(r *http.Request, v any) error {
+	var value any
+	if err := yaml.NewDecoder(r.Body).Decode(&value); err != nil {
+		return err
+	}
+	return convert(value, v)
+}
+
+func decodeMsgpack(r *http.Request, v any) error {
+	dec := msgpack.NewDecoder(r.Body)
+	dec.UseLooseInterfaceDecoding(true)
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return err
+	}
+	return convert(value, v)
+}
+
+func convert(value any, v any) error {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
+}
+
+// xmlElement is an element of a request body with its children in order.
+type xmlElement struct {
+	name     string
+	text     string
+	children []*xmlElement
+}
+
+func (e *xmlElement) child(name string) *xmlElement {
+	for _, c := range e.children {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// decodeXML reads the format encodeXML writes. The root element name is not
+// checked and unknown elements are ignored, like unknown JSON fields. As XML
+// text has no types, each value takes the type of the field it is read into;
+// values of free-form fields like attributes are read as numbers or booleans
+// when they parse as one.
+func decodeXML(r *http.Request, v any) error {
+	dec := xml.NewDecoder(r.Body)
+	var root *xmlElement
+	for root == nil {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return io.ErrUnexpectedEOF
+			}
+			return err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			if root, err = readXMLElement(dec, start); err != nil {
+				return err
+			}
+		}
+	}
+
+	value, err := xmlValue(root, reflect.TypeOf(v))
+	if err != nil {
+		return err
+	}
+	return convert(value, v)
+}
+
+func readXMLElement(dec *xml.Decoder, start xml.StartElement) (*xmlElement, error) {
+	e := &xmlElement{name: start.Name.Local}
+	var text strings.Builder
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			child, err := readXMLElement(dec, t)
+			if err != nil {
+				return nil, err
+			}
+			e.children = append(e.children, child)
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			e.text = strings.TrimSpace(text.String())
+			return e, nil
+		}
+	}
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// xmlValue converts e into the plain value encoding/json expects for t.
+func xmlValue(e *xmlElement, t reflect.Type) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		if len(e.children) == 0 && e.text == "" {
+			return nil, nil
+		}
+		t = t.Elem()
+	}
+	if t == timeType {
+		return e.text, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		out := map[string]any{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			child := e.child(name)
+			if child == nil {
+				continue
+			}
+			value, err := xmlValue(child, f.Type)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			out[name] = value
+		}
+		return out, nil
+	case reflect.Map:
+		out := map[string]any{}
+		for _, child := range e.children {
+			value, err := xmlValue(child, t.Elem())
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", child.name, err)
+			}
+			out[child.name] = value
+		}
+		return out, nil
+	case reflect.Slice, reflect.Array:
+		out := make([]any, 0, len(e.children))
+		for _, child := range e.children {
+			value, err := xmlValue(child, t.Elem())
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, value)
+		}
+		return out, nil
+	case reflect.String:
+		return e.text, nil
+	case reflect.Bool:
+		return strconv.ParseBool(e.text)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if _, err := strconv.ParseFloat(e.text, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", e.text)
+		}
+		return json.Number(e.text), nil
+	case reflect.Interface:
+		return guessXMLValue(e), nil
+	}
+	return nil, fmt.Errorf("cannot read XML into %s", t)
+}
+
+// guessXMLValue reads an element without a Go type: elements with <item>
+// children are lists, other elements with children objects, and text a
+// number or boolean when it parses as one.
+func guessXMLValue(e *xmlElement) any {
+	if len(e.children) > 0 {
+		if e.children[0].name == "item" {
+			out := make([]any, 0, len(e.children))
+			for _, child := range e.children {
+				out = append(out, guessXMLValue(child))
+			}
+			return out
+		}
+		out := map[string]any{}
+		for _, child := range e.children {
+			out[child.name] = guessXMLValue(child)
+		}
+		return out
+	}
+	if _, err := strconv.ParseFloat(e.text, 64); err == nil {
+		return json.Number(e.text)
+	}
+	if e.text == "true" || e.text == "false" {
+		return e.text == "true"
+	}
+	return e.text
+}
@@ -0,0 +1,297 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/vmihailenco/msgpack/v5"
+	"go.yaml.in/yaml/v3"
+)
+
+// Every format uses the json tags of the entities for its field names and
+// leaves out the same empty fields. XML, YAML and CSV are converted from the
+// JSON encoding of data.
+
+func encodeJSON(w *bytes.Buffer, data any) error {
+	return json.NewEncoder(w).Encode(data)
+}
+
+// encodeMsgpack encodes data directly, so times are MessagePack timestamps
+// rather than strings.
+func encodeMsgpack(w *bytes.Buffer, data any) error {
+	enc := msgpack.NewEncoder(w)
+	enc.SetCustomStructTag("json")
+	enc.UseCompactInts(true)
+	return enc.Encode(data)
+}
+
+// encodeYAML converts the JSON encoding, which is valid YAML, to block style.
+func encodeYAML(w *bytes.Buffer, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(body, &doc); err != nil {
+		return err
+	}
+	blockStyle(&doc)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func blockStyle(n *yaml.Node) {
+	n.Style &^= yaml.FlowStyle | yaml.DoubleQuotedStyle
+	for _, child := range n.Content {
+		blockStyle(child)
+	}
+}
+
+// encodeXML writes objects as elements named after their keys. Lists are
+// wrapped in an element named after their item type, so []Employee becomes
+// <employees><employee>...</employee></employees>; lists inside objects use
+// <item>. Null fields are left out.
+func encodeXML(w *bytes.Buffer, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	root, item := xmlNames(data)
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	w.WriteString(xml.Header)
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := writeXMLValue(enc, dec, root, item); err != nil {
+		return err
+	}
+	if err := enc.Flush(); err != nil {
+		return err
+	}
+	w.WriteByte('\n')
+	return nil
+}
+
+func writeXMLValue(enc *xml.Encoder, dec *json.Decoder, name, item string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	switch t := tok.(type) {
+	case nil:
+		return nil
+	case json.Delim:
+		if err := enc.EncodeToken(start); err != nil {
+			return err
+		}
+		for dec.More() {
+			child := item
+			if t == '{' {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				child = key.(string)
+			}
+			if err := writeXMLValue(enc, dec, child, "item"); err != nil {
+				return err
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		return enc.EncodeToken(start.End())
+	default:
+		return enc.EncodeElement(fmt.Sprint(t), start)
+	}
+}
+
+// xmlNames returns the root element and list item names for data.
+func xmlNames(data any) (string, string) {
+	t := reflect.TypeOf(data)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil {
+		return "response", "item"
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		if name := snakeCase(t.Name()); name != "" {
+			return name, "item"
+		}
+		return "response", "item"
+	}
+
+	elem := t.Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	if name := snakeCase(elem.Name()); name != "" {
+		return name + "s", name
+	}
+	return "items", "item"
+}
+
+func snakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// encodeCSV writes a list with one row per item and a header row. Nested
+// objects are flattened into columns like attributes.cost_center, nested
+// lists are written as JSON. Columns come from every item, so a field set on
+// only some of them still gets a column.
+func encodeCSV(w *bytes.Buffer, data any) error {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	var (
+		columns []string
+		rows    []map[string]string
+	)
+	addColumns := func(cells []cell) {
+		prev := -1
+		for _, c := range cells {
+			i := slices.Index(columns, c.key)
+			if i < 0 {
+				i = prev + 1
+				columns = slices.Insert(columns, i, c.key)
+			}
+			prev = i
+		}
+	}
+
+	// The zero item names the columns of empty lists too.
+	elem := v.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	zero, err := flatten(reflect.Zero(elem).Interface())
+	if err != nil {
+		return err
+	}
+	addColumns(zero)
+	for i := 0; i < v.Len(); i++ {
+		cells, err := flatten(v.Index(i).Interface())
+		if err != nil {
+			return err
+		}
+		addColumns(cells)
+		row := make(map[string]string, len(cells))
+		for _, c := range cells {
+			row[c.key] = c.value
+		}
+		rows = append(rows, row)
+	}
+
+	out := csv.NewWriter(w)
+	if err := out.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, row := range rows {
+		for i, column := range columns {
+			record[i] = row[column]
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+type cell struct {
+	key, value string
+}
+
+// flatten turns the JSON encoding of item into cells in field order.
+func flatten(item any) ([]cell, error) {
+	body, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var cells []cell
+	if err := flattenValue(&cells, "", body); err != nil {
+		return nil, err
+	}
+	// Items that are not objects get a single column.
+	if len(cells) == 1 && cells[0].key == "" {
+		cells[0].key = "value"
+	}
+	return cells, nil
+}
+
+func flattenValue(cells *[]cell, key string, raw json.RawMessage) error {
+	raw = bytes.TrimSpace(raw)
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		*cells = append(*cells, cell{key: key})
+	case raw[0] == '{':
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+		for dec.More() {
+			name, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			var value json.RawMessage
+			if err := dec.Decode(&value); err != nil {
+				return err
+			}
+			child := name.(string)
+			if key != "" {
+				child = key + "." + child
+			}
+			if err := flattenValue(cells, child, value); err != nil {
+				return err
+			}
+		}
+	case raw[0] == '"':
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return err
+		}
+		*cells = append(*cells, cell{key: key, value: escapeFormula(s)})
+	default:
+		*cells = append(*cells, cell{key: key, value: string(raw)})
+	}
+	return nil
+}
+
+// escapeFormula keeps spreadsheets from running text that starts like a
+// formula.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
@@ -0,0 +1,163 @@
+package protocol
+
+import (
+	"bytes"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// codec reads and writes one representation of a resource. Formats that
+// cannot express a single object, like CSV, only encode lists and have no
+// decoder.
+type codec struct {
+	encode   func(w *bytes.Buffer, data any) error
+	decode   func(r *http.Request, v any) error
+	listOnly bool
+}
+
+var (
+	jsonCodec    = &codec{encode: encodeJSON, decode: decodeJSON}
+	xmlCodec     = &codec{encode: encodeXML, decode: decodeXML}
+	yamlCodec    = &codec{encode: encodeYAML, decode: decodeYAML}
+	msgpackCodec = &codec{encode: encodeMsgpack, decode: decodeMsgpack}
+	csvCodec     = &codec{encode: encodeCSV, listOnly: true}
+)
+
+// mediaTypes lists what Write can answer with, in order of preference when
+// the Accept header rates several of them the same. Aliases answer with the
+// type the client asked for.
+var mediaTypes = []struct {
+	name  string
+	codec *codec
+}{
+	{"application/json", jsonCodec},
+	{"application/xml", xmlCodec},
+	{"text/xml", xmlCodec},
+	{"application/yaml", yamlCodec},
+	{"application/x-yaml", yamlCodec},
+	{"text/yaml", yamlCodec},
+	{"application/msgpack", msgpackCodec},
+	{"application/x-msgpack", msgpackCodec},
+	{"application/vnd.msgpack", msgpackCodec},
+	{"text/csv", csvCodec},
+}
+
+func codecFor(mediaType string) *codec {
+	for _, t := range mediaTypes {
+		if t.name == mediaType {
+			return t.codec
+		}
+	}
+	return nil
+}
+
+// Write encodes data in the format the Accept header of r prefers: JSON,
+// XML, YAML, MessagePack or, for lists, CSV. JSON is used when the header is
+// missing. Requests accepting none of them are answered with 406.
+func Write(w http.ResponseWriter, r *http.Request, status int, data any) error {
+	w.Header().Add("Vary", "Accept")
+
+	list := isList(data)
+	mediaType, c := negotiate(r.Header.Get("Accept"), list)
+	if c == nil {
+		return WriteJSONError(w, http.StatusNotAcceptable, "not acceptable, supported types are "+strings.Join(offers(list), ", "))
+	}
+	// Encode before writing the header, so a failure can still be a 500.
+	var buf bytes.Buffer
+	if err := c.encode(&buf, data); err != nil {
+		InternalServerError(w, r, err)
+		return err
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.WriteHeader(status)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func offers(list bool) []string {
+	var names []string
+	for _, t := range mediaTypes {
+		if list || !t.codec.listOnly {
+			names = append(names, t.name)
+		}
+	}
+	return names
+}
+
+type mediaRange struct {
+	typ, subtype string
+	q            float64
+}
+
+// negotiate picks the media type with the highest quality in accept. Each
+// offer is rated by the most specific range matching it, so
+// "text/*;q=0.5, text/csv" prefers CSV and "*/*, text/csv;q=0" excludes it.
+func negotiate(accept string, list bool) (string, *codec) {
+	if strings.TrimSpace(accept) == "" {
+		return "application/json", jsonCodec
+	}
+	ranges := parseAccept(accept)
+
+	var (
+		best      string
+		bestCodec *codec
+		bestQ     float64
+	)
+	for _, t := range mediaTypes {
+		if t.codec.listOnly && !list {
+			continue
+		}
+		typ, subtype, _ := strings.Cut(t.name, "/")
+		q, specificity := 0.0, -1
+		for _, mr := range ranges {
+			s := -1
+			switch {
+			case mr.typ == typ && mr.subtype == subtype:
+				s = 2
+			case mr.typ == typ && mr.subtype == "*":
+				s = 1
+			case mr.typ == "*" && mr.subtype == "*":
+				s = 0
+			}
+			if s > specificity {
+				q, specificity = mr.q, s
+			}
+		}
+		if q > bestQ {
+			best, bestCodec, bestQ = t.name, t.codec, q
+		}
+	}
+	return best, bestCodec
+}
+
+func parseAccept(accept string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		typ, subtype, ok := strings.Cut(strings.ToLower(strings.TrimSpace(params[0])), "/")
+		if !ok {
+			continue
+		}
+		mr := mediaRange{typ: typ, subtype: subtype, q: 1}
+		for _, param := range params[1:] {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if strings.EqualFold(key, "q") {
+				if q, err := strconv.ParseFloat(value, 64); err == nil && q >= 0 && q <= 1 {
+					mr.q = q
+				}
+			}
+		}
+		ranges = append(ranges, mr)
+	}
+	return ranges
+}
+
+func isList(data any) bool {
+	t := reflect.TypeOf(data)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
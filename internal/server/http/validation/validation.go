@@ -0,0 +1,297 @@
+// Package validation checks HTTP traffic against the OpenAPI document in
+// api/openapi. Requests that do not match it are answered with problem
+// details before they reach a handler; in test mode JSON answers are checked
+// as well, so a handler drifting from the document fails loudly.
+package validation
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/server/http/protocol"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"go.uber.org/zap"
+)
+
+// Middleware validates requests, and with cfg.ValidateResponses JSON
+// answers, of the operations in doc. Paths the document does not describe
+// are passed through untouched.
+func Middleware(doc *openapi3.T, cfg config.OpenAPIConfig, logger *zap.SugaredLogger) (func(http.Handler) http.Handler, error) {
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("build openapi router: %w", err)
+	}
+
+	v := &validator{router: router, cfg: cfg, logger: logger}
+	return v.middleware, nil
+}
+
+type validator struct {
+	router routers.Router
+	cfg    config.OpenAPIConfig
+	logger *zap.SugaredLogger
+}
+
+func (v *validator) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, params, err := v.router.FindRoute(r)
+		if err != nil {
+			// Unknown paths and methods are left to the router.
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: params,
+			Route:      route,
+			Options:    v.options(r),
+		}
+
+		if v.cfg.ValidateRequests {
+			if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+				status := requestStatus(err)
+				protocol.WriteProblem(w, &protocol.Problem{
+					Type:     "about:blank",
+					Title:    http.StatusText(status),
+					Status:   status,
+					Detail:   "request does not match the API specification",
+					Instance: r.URL.Path,
+					Errors:   requestErrors(err),
+				})
+				return
+			}
+		}
+
+		if !v.cfg.ValidateResponses || streams(route.Operation) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// Only JSON is checked; the other formats are encoded from the same
+		// values by the protocol package.
+		if mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type")); !isJSON(mediaType) {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		err = openapi3filter.ValidateResponse(r.Context(), &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: input,
+			Status:                 rec.status,
+			Header:                 w.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Options:                input.Options,
+		})
+		if err != nil {
+			logging.FromContext(r.Context(), v.logger).Errorw("response does not match the API specification",
+				"operation", route.Operation.OperationID, "status", rec.status, "error", err)
+			w.Header().Del("Content-Length")
+			protocol.WriteProblem(w, &protocol.Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(http.StatusInternalServerError),
+				Status:   http.StatusInternalServerError,
+				Detail:   "response does not match the API specification",
+				Instance: r.URL.Path,
+				Errors:   responseErrors(err),
+			})
+			return
+		}
+
+		w.WriteHeader(rec.status)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+func (v *validator) options(r *http.Request) *openapi3filter.Options {
+	opts := &openapi3filter.Options{
+		MultiError:            true,
+		IncludeResponseStatus: true,
+		// Authentication is enforced by the auth middleware, which answers
+		// with the API's own 401.
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+	}
+	// Uploads are streamed to storage by the handler, reading them here
+	// would hold the whole file in memory. Bodies kin-openapi cannot decode,
+	// like XML and MessagePack, are left to the handler, which answers
+	// unreadable ones itself.
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" || (mediaType != "" && openapi3filter.RegisteredBodyDecoder(mediaType) == nil) {
+		opts.ExcludeRequestBody = true
+	}
+	return opts
+}
+
+// requestStatus is 415 for bodies of a type the operation does not accept,
+// as kin-openapi's own error encoder answers them, and 400 otherwise.
+func requestStatus(err error) int {
+	errs := []error{err}
+	if multi, ok := err.(openapi3.MultiError); ok {
+		errs = multi
+	}
+	for _, e := range errs {
+		var reqErr *openapi3filter.RequestError
+		if errors.As(e, &reqErr) && strings.HasPrefix(reqErr.Reason, "header Content-Type has unexpected value") {
+			return http.StatusUnsupportedMediaType
+		}
+	}
+	return http.StatusBadRequest
+}
+
+// streams reports whether op documents event streams or downloads, whose
+// answers are not buffered.
+func streams(op *openapi3.Operation) bool {
+	if op.Responses == nil {
+		return true
+	}
+	for _, ref := range op.Responses.Map() {
+		if ref.Value == nil {
+			continue
+		}
+		for contentType := range ref.Value.Content {
+			if contentType == "text/event-stream" || contentType == "application/octet-stream" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isJSON(contentType string) bool {
+	return contentType == "application/json" || strings.HasSuffix(contentType, "+json")
+}
+
+// requestErrors flattens a validation error into one entry per invalid
+// parameter or body field.
+func requestErrors(err error) []protocol.ProblemError {
+	if multi, ok := err.(openapi3.MultiError); ok {
+		var out []protocol.ProblemError
+		for _, e := range multi {
+			out = append(out, requestErrors(e)...)
+		}
+		return out
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if !errors.As(err, &reqErr) {
+		return []protocol.ProblemError{{Message: err.Error()}}
+	}
+
+	var in, name string
+	switch {
+	case reqErr.Parameter != nil:
+		in, name = reqErr.Parameter.In, reqErr.Parameter.Name
+	case reqErr.RequestBody != nil:
+		in = "body"
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(reqErr.Err, &schemaErr) {
+		message := reqErr.Reason
+		if message == "" && reqErr.Err != nil {
+			message = reqErr.Err.Error()
+		}
+		return []protocol.ProblemError{{In: in, Name: name, Message: message}}
+	}
+
+	var out []protocol.ProblemError
+	for _, leaf := range schemaLeaves(reqErr.Err) {
+		pointer, message := describe(leaf)
+		e := protocol.ProblemError{In: in, Name: name, Message: message}
+		if in == "body" {
+			e.Name = pointer
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// responseErrors lists what is wrong with an answer. Unlike request errors
+// they are meant for whoever is testing the handler.
+func responseErrors(err error) []protocol.ProblemError {
+	leaves := schemaLeaves(err)
+	if len(leaves) == 0 {
+		var respErr *openapi3filter.ResponseError
+		if errors.As(err, &respErr) && respErr.Reason != "" {
+			return []protocol.ProblemError{{Message: respErr.Reason}}
+		}
+		return []protocol.ProblemError{{Message: err.Error()}}
+	}
+
+	out := make([]protocol.ProblemError, 0, len(leaves))
+	for _, leaf := range leaves {
+		pointer, message := describe(leaf)
+		out = append(out, protocol.ProblemError{In: "body", Name: pointer, Message: message})
+	}
+	return out
+}
+
+// schemaLeaves returns the innermost schema errors of err; a value failing
+// several keywords reports one error per keyword.
+func schemaLeaves(err error) []*openapi3.SchemaError {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var out []*openapi3.SchemaError
+		for _, e := range multi {
+			out = append(out, schemaLeaves(e)...)
+		}
+		return out
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return nil
+	}
+	if schemaErr.Origin != nil {
+		if leaves := schemaLeaves(schemaErr.Origin); len(leaves) > 0 {
+			return leaves
+		}
+	}
+	return []*openapi3.SchemaError{schemaErr}
+}
+
+// describe returns the JSON pointer and message of a schema error. The JSON
+// Schema 2020-12 validator used for 3.1 documents puts the location into the
+// reason as `error at "/salary": at '/salary': ...`.
+func describe(err *openapi3.SchemaError) (string, string) {
+	pointer := "/" + strings.Join(err.JSONPointer(), "/")
+	message := err.Reason
+	if rest, ok := strings.CutPrefix(message, "error at "); ok {
+		if _, after, found := strings.Cut(rest, ": "); found {
+			message = after
+		}
+	}
+	if rest, ok := strings.CutPrefix(message, "at '"); ok {
+		if location, after, found := strings.Cut(rest, "': "); found {
+			pointer, message = location, after
+		}
+	}
+	if pointer == "" {
+		pointer = "/"
+	}
+	return pointer, message
+}
+
+// recorder holds an answer back until it has been validated.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) { r.status = status }
+
+func (r *recorder) Write(p []byte) (int, error) { return r.body.Write(p) }
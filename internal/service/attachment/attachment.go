@@ -0,0 +1,266 @@
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/storage/blob"
+	"go.uber.org/zap"
+)
+
+type attachmentService struct {
+	repo      repository.AttachmentRepository
+	employees repository.EmployeeRepository
+	blobs     blob.Store
+	cfg       config.AttachmentConfig
+	logger    *zap.SugaredLogger
+}
+
+func NewAttachmentService(
+	repo repository.AttachmentRepository,
+	employees repository.EmployeeRepository,
+	blobs blob.Store,
+	cfg config.AttachmentConfig,
+	logger *zap.SugaredLogger,
+) *attachmentService {
+	return &attachmentService{
+		repo:      repo,
+		employees: employees,
+		blobs:     blobs,
+		cfg:       cfg,
+		logger:    logger,
+	}
+}
+
+func (a *attachmentService) GetByEmployee(ctx context.Context, employeeID int64) ([]attachmentEntity.Attachment, error) {
+	if employeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+
+	if _, err := a.employees.GetById(ctx, employeeID); err != nil {
+		return nil, err
+	}
+
+	return a.repo.GetByEmployee(ctx, employeeID)
+}
+
+// Upload streams the body into blob storage while hashing it. The content
+// type is sniffed from the first bytes rather than trusted from the client,
+// and the blob is removed again whenever the upload is rejected.
+func (a *attachmentService) Upload(ctx context.Context, up *attachmentEntity.Upload) (*attachmentEntity.Attachment, error) {
+	if up.EmployeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+
+	up.Category = strings.ToLower(strings.TrimSpace(up.Category))
+	if up.Category == "" {
+		up.Category = attachmentEntity.CategoryOther
+	}
+	if !attachmentEntity.ValidCategory(up.Category) {
+		return nil, errors.New("category must be one of contract, id_scan, photo or other")
+	}
+
+	up.Filename = strings.TrimSpace(filepath.Base(filepath.Clean("/" + up.Filename)))
+	if up.Filename == "" || up.Filename == "/" {
+		return nil, errors.New("filename is required")
+	}
+
+	up.ExpectedSHA256 = strings.ToLower(strings.TrimSpace(up.ExpectedSHA256))
+	if up.ExpectedSHA256 != "" {
+		if _, err := hex.DecodeString(up.ExpectedSHA256); err != nil || len(up.ExpectedSHA256) != sha256.Size*2 {
+			return nil, errors.New("sha256 must be a hex encoded SHA-256 digest")
+		}
+	}
+
+	if _, err := a.employees.GetById(ctx, up.EmployeeID); err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(up.Body, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.New("file is empty")
+		}
+		return nil, err
+	}
+	head = head[:n]
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !slices.Contains(a.cfg.AllowedTypes, contentType) {
+		return nil, repository.ErrUnsupportedMediaType
+	}
+
+	key, err := newKey(up.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	body := io.LimitReader(io.MultiReader(bytes.NewReader(head), up.Body), a.cfg.MaxBytes+1)
+	size, err := a.blobs.Put(ctx, key, io.TeeReader(body, hash))
+	if err != nil {
+		a.discard(key)
+		return nil, err
+	}
+	if size > a.cfg.MaxBytes {
+		a.discard(key)
+		return nil, repository.ErrAttachmentTooLarge
+	}
+
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if up.ExpectedSHA256 != "" && up.ExpectedSHA256 != sum {
+		a.discard(key)
+		return nil, repository.ErrChecksumMismatch
+	}
+
+	att := &attachmentEntity.Attachment{
+		EmployeeID:  up.EmployeeID,
+		Filename:    up.Filename,
+		Category:    up.Category,
+		ContentType: contentType,
+		SizeBytes:   size,
+		SHA256:      sum,
+		StorageKey:  key,
+		UploadedBy:  up.UploadedBy,
+	}
+	if err := a.repo.Create(ctx, att); err != nil {
+		a.discard(key)
+		return nil, err
+	}
+
+	return att, nil
+}
+
+// Open returns the metadata and the stored blob. A blob whose size differs
+// from what was recorded at upload is reported as a checksum mismatch right
+// away; one whose content differs fails the read that would complete it
+// with ErrChecksumMismatch, see verifiedObject.
+func (a *attachmentService) Open(ctx context.Context, employeeID int64, id int64) (*attachmentEntity.Attachment, blob.Object, error) {
+	if employeeID <= 0 || id <= 0 {
+		return nil, nil, errors.New("invalid attachment id")
+	}
+
+	att, err := a.repo.GetById(ctx, employeeID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	obj, err := a.blobs.Open(ctx, att.StorageKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, nil, repository.ErrNotFound
+		}
+		return nil, nil, err
+	}
+	if obj.Size() != att.SizeBytes {
+		obj.Close()
+		return nil, nil, repository.ErrChecksumMismatch
+	}
+
+	return att, &verifiedObject{
+		Object: obj,
+		want:   att.SHA256,
+		size:   att.SizeBytes,
+		hash:   sha256.New(),
+		verify: true,
+		mismatch: func() {
+			logging.FromContext(ctx, a.logger).Errorw("attachment content does not match its checksum",
+				"employee_id", att.EmployeeID, "id", att.ID, "storage_key", att.StorageKey)
+		},
+	}, nil
+}
+
+func (a *attachmentService) Delete(ctx context.Context, employeeID int64, id int64) error {
+	if employeeID <= 0 || id <= 0 {
+		return errors.New("invalid attachment id")
+	}
+
+	att, err := a.repo.GetById(ctx, employeeID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := a.repo.Delete(ctx, employeeID, id); err != nil {
+		return err
+	}
+
+	// The attachment is gone once its row is, a file left behind is only
+	// logged.
+	if err := a.blobs.Delete(context.WithoutCancel(ctx), att.StorageKey); err != nil {
+		logging.FromContext(ctx, a.logger).Errorw("failed to remove attachment file", "employee_id", employeeID, "id", id, "error", err)
+	}
+	return nil
+}
+
+// discard removes a rejected upload. It uses its own context because the
+// request context may be the reason the upload failed.
+func (a *attachmentService) discard(key string) {
+	_ = a.blobs.Delete(context.Background(), key)
+}
+
+// verifiedObject hashes a blob while it is read from the start. The read
+// that would complete it returns ErrChecksumMismatch instead when the content
+// is not what was uploaded, so a corrupted file is never served in full.
+// Reads that do not start at the beginning, like Range requests, are not
+// verified.
+type verifiedObject struct {
+	blob.Object
+	want     string
+	size     int64
+	hash     hash.Hash
+	read     int64
+	verify   bool
+	mismatch func()
+}
+
+func (o *verifiedObject) Seek(offset int64, whence int) (int64, error) {
+	pos, err := o.Object.Seek(offset, whence)
+	if err == nil {
+		o.hash.Reset()
+		o.read = 0
+		o.verify = pos == 0
+	}
+	return pos, err
+}
+
+func (o *verifiedObject) Read(p []byte) (int, error) {
+	n, err := o.Object.Read(p)
+	if !o.verify {
+		return n, err
+	}
+
+	o.hash.Write(p[:n])
+	o.read += int64(n)
+	if o.read >= o.size {
+		o.verify = false
+		if hex.EncodeToString(o.hash.Sum(nil)) != o.want {
+			o.mismatch()
+			return 0, repository.ErrChecksumMismatch
+		}
+	}
+	return n, err
+}
+
+func newKey(employeeID int64) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+	return attachmentEntity.EmployeeKeyPrefix(employeeID) + hex.EncodeToString(random), nil
+}
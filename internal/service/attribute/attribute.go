@@ -0,0 +1,46 @@
+package attribute
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+type attributeService struct {
+	repo repository.AttributeRepository
+}
+
+func NewAttributeService(repo repository.AttributeRepository) *attributeService {
+	return &attributeService{
+		repo: repo,
+	}
+}
+
+func (a *attributeService) GetDefinitions(ctx context.Context) ([]attributeEntity.Definition, error) {
+	return a.repo.GetDefinitions(ctx)
+}
+
+func (a *attributeService) CreateDefinition(ctx context.Context, def *attributeEntity.Definition) error {
+	def.Name = strings.ToLower(strings.TrimSpace(def.Name))
+	def.Type = strings.ToLower(strings.TrimSpace(def.Type))
+	for i := range def.EnumValues {
+		def.EnumValues[i] = strings.TrimSpace(def.EnumValues[i])
+	}
+
+	if err := attributeEntity.CheckDefinition(def); err != nil {
+		return err
+	}
+
+	return a.repo.CreateDefinition(ctx, def)
+}
+
+func (a *attributeService) DeleteDefinition(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return errors.New("invalid attribute id")
+	}
+
+	return a.repo.DeleteDefinition(ctx, id)
+}
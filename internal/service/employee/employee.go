@@ -2,30 +2,80 @@ package employee
 
 import (
 	"context"
-	"errors"
+	"fmt"
 	"strings"
 
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
 	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/logging"
 	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/storage/blob"
+	"go.uber.org/zap"
 )
 
 type employeeService struct {
 	repo repository.EmployeeRepository
+	attributes repository.AttributeRepository
+	blobs blob.Store
+	logger *zap.SugaredLogger
 }
 
-func NewEmployeeService(repo repository.EmployeeRepository) *employeeService {
+func NewEmployeeService(
+	repo repository.EmployeeRepository,
+	attributes repository.AttributeRepository,
+	blobs blob.Store,
+	logger *zap.SugaredLogger,
+) *employeeService {
 	return &employeeService{
 		repo: repo,
+		attributes: attributes,
+		blobs: blobs,
+		logger: logger,
 	}
 }
 
-func (e *employeeService) GetAll(ctx context.Context) ([]employeeEntity.Employee, error) {
-	return e.repo.GetAll(ctx)
+// GetAll expects attribute filter values as raw query strings and converts
+// them to the type of their definition before querying.
+func (e *employeeService) GetAll(ctx context.Context, filter employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	if len(filter.Attributes) > 0 {
+		defs, err := e.attributes.GetDefinitions(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		byName := make(map[string]*attributeEntity.Definition, len(defs))
+		for i := range defs {
+			byName[defs[i].Name] = &defs[i]
+		}
+
+		typed := make(map[string]any, len(filter.Attributes))
+		invalid := &attributeEntity.ValidationError{}
+		for name, raw := range filter.Attributes {
+			def, ok := byName[name]
+			if !ok {
+				invalid.Problems = append(invalid.Problems, fmt.Sprintf("%s is not a defined attribute", name))
+				continue
+			}
+			value, err := attributeEntity.ParseFilter(def, fmt.Sprint(raw))
+			if err != nil {
+				invalid.Problems = append(invalid.Problems, err.Error())
+				continue
+			}
+			typed[name] = value
+		}
+		if len(invalid.Problems) > 0 {
+			return nil, invalid
+		}
+		filter.Attributes = typed
+	}
+
+	return e.repo.GetAll(ctx, filter)
 }
 
 func (e *employeeService) GetById(ctx context.Context, id int64) (*employeeEntity.Employee, error){
 	if id <= 0 {
-		return nil, errors.New("invalid employee id")
+		return nil, employeeEntity.ValidationError("invalid employee id")
 	}
 
 	return e.repo.GetById(ctx, id)
@@ -34,35 +84,42 @@ func (e *employeeService) GetById(ctx context.Context, id int64) (*employeeEntit
 func (e *employeeService) Create(ctx context.Context, emp *employeeEntity.Employee) error {
 	//vlidaiton
 	if strings.TrimSpace(emp.Name) == "" {
-		return errors.New("name is required")
+		return employeeEntity.ValidationError("name is required")
 	}
 	if strings.TrimSpace(emp.Email) == "" {
-		return errors.New("email is required")
+		return employeeEntity.ValidationError("email is required")
 	}
 	if emp.Salary < 0 || emp.Salary == 0 {
-		return errors.New("invalid salary")
+		return employeeEntity.ValidationError("invalid salary")
 	}
 
 	emp.Name = strings.TrimSpace(emp.Name)
 	emp.Email = strings.ToLower(strings.TrimSpace(emp.Email))
 	emp.Position = strings.TrimSpace(emp.Position)
 
+	if err := e.validateAttributes(ctx, emp); err != nil {
+		return err
+	}
+
 	return e.repo.Create(ctx, emp)
 }
 
 func (e *employeeService) Update(ctx context.Context, emp *employeeEntity.Employee) error {
 	// Validation
 	if emp.ID <= 0 {
-		return errors.New("invalid employee ID")
+		return employeeEntity.ValidationError("invalid employee ID")
 	}
 	if strings.TrimSpace(emp.Name) == "" {
-		return errors.New("name is required")
+		return employeeEntity.ValidationError("name is required")
 	}
 	if strings.TrimSpace(emp.Email) == "" {
-		return errors.New("email is required")
+		return employeeEntity.ValidationError("email is required")
 	}
 	if emp.Salary < 0 || emp.Salary == 0{
-		return errors.New("invalid salary")
+		return employeeEntity.ValidationError("invalid salary")
+	}
+	if emp.ManagerID != nil && *emp.ManagerID == emp.ID {
+		return employeeEntity.ValidationError("employee cannot be their own manager")
 	}
 
 	// Normalize data
@@ -70,12 +127,16 @@ func (e *employeeService) Update(ctx context.Context, emp *employeeEntity.Employ
 	emp.Email = strings.ToLower(strings.TrimSpace(emp.Email))
 	emp.Position = strings.TrimSpace(emp.Position)
 
+	if err := e.validateAttributes(ctx, emp); err != nil {
+		return err
+	}
+
 	return e.repo.Update(ctx, emp)
 }
 
 func (e *employeeService) Delete(ctx context.Context, id int64) error {
 	if id <= 0 {
-		return errors.New("invalid employee id")
+		return employeeEntity.ValidationError("invalid employee id")
 	}
 
 
@@ -83,5 +144,24 @@ func (e *employeeService) Delete(ctx context.Context, id int64) error {
 	if err != nil {
 		return err
 	}
-	return e.repo.Delete(ctx, id)
-}
\ No newline at end of file
+	if err := e.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	// Attachment rows go with the employee through the foreign key, the
+	// files have to be removed here. The employee is gone either way, so a
+	// failure only leaves orphaned files behind and is logged.
+	if err := e.blobs.DeletePrefix(context.WithoutCancel(ctx), attachmentEntity.EmployeeKeyPrefix(id)); err != nil {
+		logging.FromContext(ctx, e.logger).Errorw("failed to remove attachments of deleted employee", "employee_id", id, "error", err)
+	}
+	return nil
+}
+
+func (e *employeeService) validateAttributes(ctx context.Context, emp *employeeEntity.Employee) error {
+	defs, err := e.attributes.GetDefinitions(ctx)
+	if err != nil {
+		return err
+	}
+
+	return attributeEntity.Validate(emp.Attributes, defs)
+}
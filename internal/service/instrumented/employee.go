@@ -0,0 +1,75 @@
+// Package instrumented decorates services with tracing spans, leaving the
+// services themselves untouched.
+package instrumented
+
+import (
+	"context"
+	"errors"
+
+	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	repository "github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/service"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/MaulanaAhmadSulami/juke_test.git/internal/service")
+
+type employeesService struct {
+	next service.EmployeesService
+}
+
+func NewEmployeesService(next service.EmployeesService) service.EmployeesService {
+	return &employeesService{next: next}
+}
+
+func start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "EmployeesService."+method, trace.WithAttributes(attrs...))
+}
+
+func end(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, repository.ErrNotFound) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (s *employeesService) GetAll(ctx context.Context, filter employeeEntity.ListFilter) ([]employeeEntity.Employee, error) {
+	ctx, span := start(ctx, "GetAll", attribute.Int("employee.filter.attributes", len(filter.Attributes)))
+	employees, err := s.next.GetAll(ctx, filter)
+	span.SetAttributes(attribute.Int("employee.count", len(employees)))
+	end(span, err)
+	return employees, err
+}
+
+func (s *employeesService) GetById(ctx context.Context, id int64) (*employeeEntity.Employee, error) {
+	ctx, span := start(ctx, "GetById", attribute.Int64("employee.id", id))
+	employee, err := s.next.GetById(ctx, id)
+	end(span, err)
+	return employee, err
+}
+
+func (s *employeesService) Create(ctx context.Context, employee *employeeEntity.Employee) error {
+	ctx, span := start(ctx, "Create")
+	err := s.next.Create(ctx, employee)
+	span.SetAttributes(attribute.Int64("employee.id", employee.ID))
+	end(span, err)
+	return err
+}
+
+func (s *employeesService) Update(ctx context.Context, employee *employeeEntity.Employee) error {
+	ctx, span := start(ctx, "Update", attribute.Int64("employee.id", employee.ID))
+	err := s.next.Update(ctx, employee)
+	end(span, err)
+	return err
+}
+
+func (s *employeesService) Delete(ctx context.Context, id int64) error {
+	ctx, span := start(ctx, "Delete", attribute.Int64("employee.id", id))
+	err := s.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
@@ -0,0 +1,145 @@
+package leave
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	leaveEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/leaves"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+type leaveService struct {
+	repo      repository.LeaveRepository
+	employees repository.EmployeeRepository
+}
+
+func NewLeaveService(repo repository.LeaveRepository, employees repository.EmployeeRepository) *leaveService {
+	return &leaveService{
+		repo:      repo,
+		employees: employees,
+	}
+}
+
+func (l *leaveService) GetTypes(ctx context.Context) ([]leaveEntity.LeaveType, error) {
+	return l.repo.GetTypes(ctx)
+}
+
+func (l *leaveService) CreateType(ctx context.Context, lt *leaveEntity.LeaveType) error {
+	lt.Name = strings.TrimSpace(lt.Name)
+	lt.AccrualMethod = strings.ToLower(strings.TrimSpace(lt.AccrualMethod))
+	if lt.AccrualMethod == "" {
+		lt.AccrualMethod = leaveEntity.AccrualAnnual
+	}
+
+	if lt.Name == "" {
+		return errors.New("name is required")
+	}
+	if lt.AnnualDays < 0 {
+		return errors.New("annual days cannot be negative")
+	}
+	if lt.MaxCarryOver < 0 {
+		return errors.New("max carry over cannot be negative")
+	}
+	if lt.AccrualMethod != leaveEntity.AccrualAnnual && lt.AccrualMethod != leaveEntity.AccrualMonthly {
+		return errors.New("accrual method must be annual or monthly")
+	}
+
+	return l.repo.CreateType(ctx, lt)
+}
+
+func (l *leaveService) GetRequestById(ctx context.Context, id int64) (*leaveEntity.LeaveRequest, error) {
+	if id <= 0 {
+		return nil, errors.New("invalid leave request id")
+	}
+
+	return l.repo.GetRequestById(ctx, id)
+}
+
+func (l *leaveService) GetRequestsByEmployee(ctx context.Context, employeeID int64) ([]leaveEntity.LeaveRequest, error) {
+	if employeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+
+	if _, err := l.employees.GetById(ctx, employeeID); err != nil {
+		return nil, err
+	}
+
+	return l.repo.GetRequestsByEmployee(ctx, employeeID)
+}
+
+func (l *leaveService) CreateRequest(ctx context.Context, req *leaveEntity.LeaveRequest) error {
+	if req.EmployeeID <= 0 {
+		return errors.New("invalid employee id")
+	}
+	if req.LeaveTypeID <= 0 {
+		return errors.New("leave type is required")
+	}
+	if req.StartDate.IsZero() || req.EndDate.IsZero() {
+		return errors.New("start and end date are required")
+	}
+
+	req.StartDate = truncateDate(req.StartDate)
+	req.EndDate = truncateDate(req.EndDate)
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	if req.EndDate.Before(req.StartDate) {
+		return errors.New("end date cannot be before start date")
+	}
+	if req.StartDate.Year() != req.EndDate.Year() {
+		return errors.New("leave request cannot span calendar years")
+	}
+
+	req.Days = leaveEntity.WorkingDays(req.StartDate, req.EndDate)
+	if req.Days == 0 {
+		return errors.New("leave request contains no working days")
+	}
+
+	return l.repo.CreateRequest(ctx, req)
+}
+
+func (l *leaveService) Approve(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error) {
+	if err := validateDecision(requestID, approverID); err != nil {
+		return nil, err
+	}
+
+	return l.repo.Approve(ctx, requestID, approverID)
+}
+
+func (l *leaveService) Reject(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error) {
+	if err := validateDecision(requestID, approverID); err != nil {
+		return nil, err
+	}
+
+	return l.repo.Reject(ctx, requestID, approverID)
+}
+
+func (l *leaveService) GetBalances(ctx context.Context, employeeID int64, year int) ([]leaveEntity.LeaveBalance, error) {
+	if employeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+	if year == 0 {
+		year = time.Now().Year()
+	}
+
+	return l.repo.GetBalances(ctx, employeeID, year)
+}
+
+// validateDecision checks the ids of an approval or rejection. That the
+// approver manages the employee is checked by the repository, in the
+// transaction recording the decision.
+func validateDecision(requestID int64, approverID int64) error {
+	if requestID <= 0 {
+		return errors.New("invalid leave request id")
+	}
+	if approverID <= 0 {
+		return errors.New("approver is required")
+	}
+
+	return nil
+}
+
+func truncateDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
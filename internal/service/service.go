@@ -2,19 +2,73 @@ package service
 
 import (
 	"context"
+	"time"
 
+	attachmentEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attachments"
+	attributeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/attributes"
 	employeeEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/employees"
+	leaveEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/leaves"
+	timeEntryEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/timeentries"
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/storage/blob"
 )
 
 type EmployeesService interface {
-	GetAll(context.Context) ([]employeeEntity.Employee, error)
+	GetAll(context.Context, employeeEntity.ListFilter) ([]employeeEntity.Employee, error)
 	GetById(context.Context, int64) (*employeeEntity.Employee, error)
 	Create(context.Context, *employeeEntity.Employee) error
 	Update(context.Context, *employeeEntity.Employee) error
 	Delete(context.Context, int64) error
 }
 
+type AttributeService interface {
+	GetDefinitions(context.Context) ([]attributeEntity.Definition, error)
+	CreateDefinition(context.Context, *attributeEntity.Definition) error
+	DeleteDefinition(context.Context, int64) error
+}
+
+type AttachmentService interface {
+	GetByEmployee(context.Context, int64) ([]attachmentEntity.Attachment, error)
+	Upload(context.Context, *attachmentEntity.Upload) (*attachmentEntity.Attachment, error)
+	Open(ctx context.Context, employeeID int64, id int64) (*attachmentEntity.Attachment, blob.Object, error)
+	Delete(ctx context.Context, employeeID int64, id int64) error
+}
+
+type LeaveService interface {
+	GetTypes(context.Context) ([]leaveEntity.LeaveType, error)
+	CreateType(context.Context, *leaveEntity.LeaveType) error
+	GetRequestById(context.Context, int64) (*leaveEntity.LeaveRequest, error)
+	GetRequestsByEmployee(context.Context, int64) ([]leaveEntity.LeaveRequest, error)
+	CreateRequest(context.Context, *leaveEntity.LeaveRequest) error
+	Approve(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error)
+	Reject(ctx context.Context, requestID int64, approverID int64) (*leaveEntity.LeaveRequest, error)
+	GetBalances(ctx context.Context, employeeID int64, year int) ([]leaveEntity.LeaveBalance, error)
+}
+
+type TimeEntryService interface {
+	GetByEmployee(ctx context.Context, employeeID int64, from time.Time, to time.Time) ([]timeEntryEntity.TimeEntry, error)
+	Start(ctx context.Context, employeeID int64, note string) (*timeEntryEntity.TimeEntry, error)
+	Stop(ctx context.Context, employeeID int64) (*timeEntryEntity.TimeEntry, error)
+	Update(context.Context, *timeEntryEntity.TimeEntry) error
+	Timesheet(ctx context.Context, employeeID int64, from time.Time, to time.Time) (*timeEntryEntity.Timesheet, error)
+}
+
+type WebhookService interface {
+	GetAll(context.Context) ([]webhookEntity.Subscription, error)
+	GetById(context.Context, int64) (*webhookEntity.Subscription, error)
+	Create(context.Context, *webhookEntity.Subscription) error
+	Update(context.Context, *webhookEntity.Subscription) error
+	Delete(context.Context, int64) error
+	GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhookEntity.Delivery, error)
+	SendTest(ctx context.Context, subscriptionID int64) (*webhookEntity.Delivery, error)
+	Redeliver(ctx context.Context, subscriptionID int64, id int64) (*webhookEntity.Delivery, error)
+}
 
 type Service struct {
 	EmployeesService EmployeesService
+	LeaveService LeaveService
+	TimeEntryService TimeEntryService
+	AttributeService AttributeService
+	AttachmentService AttachmentService
+	WebhookService WebhookService
 }
\ No newline at end of file
@@ -0,0 +1,133 @@
+package timeentry
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	timeEntryEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/timeentries"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+type timeEntryService struct {
+	repo      repository.TimeEntryRepository
+	employees repository.EmployeeRepository
+	cfg       config.AttendanceConfig
+}
+
+func NewTimeEntryService(
+	repo repository.TimeEntryRepository,
+	employees repository.EmployeeRepository,
+	cfg config.AttendanceConfig,
+) *timeEntryService {
+	return &timeEntryService{
+		repo:      repo,
+		employees: employees,
+		cfg:       cfg,
+	}
+}
+
+func (t *timeEntryService) GetByEmployee(ctx context.Context, employeeID int64, from time.Time, to time.Time) ([]timeEntryEntity.TimeEntry, error) {
+	if err := t.validateRange(ctx, employeeID, from, to); err != nil {
+		return nil, err
+	}
+
+	return t.repo.GetByEmployee(ctx, employeeID, from, to.AddDate(0, 0, 1))
+}
+
+func (t *timeEntryService) Start(ctx context.Context, employeeID int64, note string) (*timeEntryEntity.TimeEntry, error) {
+	if employeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+
+	entry := &timeEntryEntity.TimeEntry{
+		EmployeeID: employeeID,
+		ClockIn:    time.Now().UTC().Truncate(time.Second),
+		Note:       strings.TrimSpace(note),
+	}
+	if t.locked(entry.ClockIn) {
+		return nil, repository.ErrTimeEntryLocked
+	}
+
+	if err := t.repo.Start(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (t *timeEntryService) Stop(ctx context.Context, employeeID int64) (*timeEntryEntity.TimeEntry, error) {
+	if employeeID <= 0 {
+		return nil, errors.New("invalid employee id")
+	}
+
+	open, err := t.repo.GetOpen(ctx, employeeID)
+	if err != nil {
+		return nil, err
+	}
+	if t.locked(open.ClockIn) {
+		return nil, repository.ErrTimeEntryLocked
+	}
+
+	return t.repo.Stop(ctx, employeeID, time.Now().UTC().Truncate(time.Second))
+}
+
+// Update adjusts clock in/out times and the note of an entry. Both the stored
+// and the new clock in time must be on or after the lock date.
+func (t *timeEntryService) Update(ctx context.Context, entry *timeEntryEntity.TimeEntry) error {
+	if entry.ID <= 0 {
+		return errors.New("invalid time entry id")
+	}
+	if entry.ClockIn.IsZero() {
+		return errors.New("clock_in is required")
+	}
+	if entry.ClockOut != nil && !entry.ClockOut.After(entry.ClockIn) {
+		return errors.New("clock_out must be after clock_in")
+	}
+	if entry.ClockOut != nil && entry.ClockOut.After(time.Now()) {
+		return errors.New("clock_out cannot be in the future")
+	}
+
+	existing, err := t.repo.GetById(ctx, entry.ID)
+	if err != nil {
+		return err
+	}
+	if t.locked(existing.ClockIn) || t.locked(entry.ClockIn) {
+		return repository.ErrTimeEntryLocked
+	}
+
+	entry.EmployeeID = existing.EmployeeID
+	entry.Note = strings.TrimSpace(entry.Note)
+
+	return t.repo.Update(ctx, entry)
+}
+
+// Timesheet reports on the whole weeks around from and to, overtime is only
+// meaningful per complete week.
+func (t *timeEntryService) Timesheet(ctx context.Context, employeeID int64, from time.Time, to time.Time) (*timeEntryEntity.Timesheet, error) {
+	from, to = timeEntryEntity.AlignToWeeks(from, to)
+	entries, err := t.GetByEmployee(ctx, employeeID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return timeEntryEntity.BuildTimesheet(employeeID, from, to, entries, t.cfg.WeeklyHours), nil
+}
+
+func (t *timeEntryService) validateRange(ctx context.Context, employeeID int64, from time.Time, to time.Time) error {
+	if employeeID <= 0 {
+		return errors.New("invalid employee id")
+	}
+	if to.Before(from) {
+		return errors.New("to cannot be before from")
+	}
+
+	_, err := t.employees.GetById(ctx, employeeID)
+	return err
+}
+
+func (t *timeEntryService) locked(clockIn time.Time) bool {
+	return !t.cfg.LockDate.IsZero() && clockIn.Before(t.cfg.LockDate)
+}
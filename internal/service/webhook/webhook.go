@@ -0,0 +1,160 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/config"
+	webhookEntity "github.com/MaulanaAhmadSulami/juke_test.git/internal/entities/webhooks"
+	"github.com/MaulanaAhmadSulami/juke_test.git/internal/repository/postgres"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+type webhookService struct {
+	repo repository.WebhookRepository
+	cfg  config.WebhookConfig
+}
+
+func NewWebhookService(repo repository.WebhookRepository, cfg config.WebhookConfig) *webhookService {
+	return &webhookService{
+		repo: repo,
+		cfg:  cfg,
+	}
+}
+
+func (w *webhookService) GetAll(ctx context.Context) ([]webhookEntity.Subscription, error) {
+	return w.repo.GetAll(ctx)
+}
+
+func (w *webhookService) GetById(ctx context.Context, id int64) (*webhookEntity.Subscription, error) {
+	if id <= 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return w.repo.GetById(ctx, id)
+}
+
+// Create generates the signing secret, which is only returned here.
+func (w *webhookService) Create(ctx context.Context, sub *webhookEntity.Subscription) error {
+	if err := w.check(sub); err != nil {
+		return err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	sub.Secret = hex.EncodeToString(secret)
+
+	return w.repo.Create(ctx, sub)
+}
+
+func (w *webhookService) Update(ctx context.Context, sub *webhookEntity.Subscription) error {
+	if sub.ID <= 0 {
+		return repository.ErrNotFound
+	}
+
+	if err := w.check(sub); err != nil {
+		return err
+	}
+
+	sub.Secret = ""
+	return w.repo.Update(ctx, sub)
+}
+
+func (w *webhookService) Delete(ctx context.Context, id int64) error {
+	if id <= 0 {
+		return repository.ErrNotFound
+	}
+
+	return w.repo.Delete(ctx, id)
+}
+
+func (w *webhookService) GetDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]webhookEntity.Delivery, error) {
+	if _, err := w.GetById(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case limit <= 0:
+		limit = defaultDeliveryLimit
+	case limit > maxDeliveryLimit:
+		limit = maxDeliveryLimit
+	}
+
+	return w.repo.GetDeliveries(ctx, subscriptionID, limit)
+}
+
+// SendTest queues a webhook.test event for the subscription and returns the
+// pending delivery; the deliverer sends it with its next poll. It is sent
+// even when the subscription is inactive and is not retried.
+func (w *webhookService) SendTest(ctx context.Context, subscriptionID int64) (*webhookEntity.Delivery, error) {
+	sub, err := w.GetById(ctx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]any{
+		"type":            webhookEntity.EventTest,
+		"subscription_id": sub.ID,
+		"created_at":      time.Now().UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	delivery := &webhookEntity.Delivery{
+		SubscriptionID: sub.ID,
+		EventType:      webhookEntity.EventTest,
+		Payload:        payload,
+	}
+	if err := w.repo.CreateDelivery(ctx, delivery); err != nil {
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// Redeliver queues a delivery again with a fresh set of attempts, dead
+// ones included.
+func (w *webhookService) Redeliver(ctx context.Context, subscriptionID int64, id int64) (*webhookEntity.Delivery, error) {
+	if subscriptionID <= 0 || id <= 0 {
+		return nil, repository.ErrNotFound
+	}
+
+	return w.repo.Redeliver(ctx, subscriptionID, id)
+}
+
+func (w *webhookService) check(sub *webhookEntity.Subscription) error {
+	normalize(sub)
+	if err := webhookEntity.Check(sub); err != nil {
+		return err
+	}
+	if w.cfg.AllowPrivateNetworks {
+		return nil
+	}
+	return webhookEntity.CheckHost(sub)
+}
+
+func normalize(sub *webhookEntity.Subscription) {
+	sub.URL = strings.TrimSpace(sub.URL)
+	sub.Description = strings.TrimSpace(sub.Description)
+
+	events := []string{}
+	for _, evt := range sub.Events {
+		evt = strings.ToLower(strings.TrimSpace(evt))
+		if evt != "" && !slices.Contains(events, evt) {
+			events = append(events, evt)
+		}
+	}
+	sub.Events = events
+}
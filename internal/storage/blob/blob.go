@@ -0,0 +1,29 @@
+// Package blob stores opaque binary objects under slash separated keys.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+var ErrNotFound = errors.New("blob not found")
+
+// Object is an open blob. It supports seeking so it can be served with
+// range requests.
+type Object interface {
+	io.ReadSeekCloser
+	Size() int64
+	ModTime() time.Time
+}
+
+type Store interface {
+	// Put writes everything read from r under key and returns the number of
+	// bytes written. An existing blob under the same key is replaced.
+	Put(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+	// DeletePrefix removes every blob whose key starts with prefix.
+	DeletePrefix(ctx context.Context, prefix string) error
+}
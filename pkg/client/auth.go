@@ -0,0 +1,23 @@
+package client
+
+import "net/http"
+
+// Authenticator adds credentials to a request before it is sent.
+type Authenticator interface {
+	Authenticate(req *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator, e.g. to fetch a
+// short-lived token per request.
+type AuthenticatorFunc func(req *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(req *http.Request) error { return f(req) }
+
+// BearerToken sends token as "Authorization: Bearer <token>", the scheme of
+// API_TOKENS.
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
@@ -0,0 +1,133 @@
+// Package client is a Go client for the employee API.
+//
+//	c := client.New("http://localhost:8080", client.WithAuth(client.BearerToken("change-me")))
+//	emps, err := c.ListEmployees(ctx, &client.ListEmployeesParams{Attr: map[string]string{"cost_center": "CC-100"}})
+//
+// The typed methods and types in employees.gen.go are generated from
+// api/openapi/openapi.yaml, see generate.go. Failed calls return an *APIError when
+// the API answered, and the transport error otherwise.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Client calls the employee API. It is safe for concurrent use.
+type Client struct {
+	baseURL   string
+	http      *http.Client
+	auth      Authenticator
+	retry     RetryPolicy
+	userAgent string
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sends requests through hc instead of a client with a 30s
+// timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.http = hc }
+}
+
+// WithAuth authenticates every request, including retries.
+func WithAuth(auth Authenticator) Option {
+	return func(c *Client) { c.auth = auth }
+}
+
+// WithRetry replaces DefaultRetryPolicy; RetryPolicy{} disables retries.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) { c.retry = policy }
+}
+
+// WithUserAgent sets the User-Agent header.
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New returns a client for the API at baseURL, e.g. http://localhost:8080;
+// the /api/v1 prefix is added.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:   strings.TrimRight(baseURL, "/") + basePath,
+		http:      &http.Client{Timeout: 30 * time.Second},
+		retry:     DefaultRetryPolicy,
+		userAgent: "employee-api-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// do sends a request and decodes a JSON answer into out, which may be nil.
+// body is encoded once so it can be sent again on retries.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("cannot encode request: %w", err)
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		var reader io.Reader
+		if payload != nil {
+			reader = bytes.NewReader(payload)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, target, reader)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/json")
+		if payload != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.userAgent != "" {
+			req.Header.Set("User-Agent", c.userAgent)
+		}
+		if c.auth != nil {
+			if err := c.auth.Authenticate(req); err != nil {
+				return fmt.Errorf("cannot authenticate request: %w", err)
+			}
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			defer resp.Body.Close()
+			if out == nil || resp.StatusCode == http.StatusNoContent {
+				return nil
+			}
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return fmt.Errorf("cannot decode %s %s answer: %w", method, path, err)
+			}
+			return nil
+		}
+
+		apiErr := newAPIError(resp)
+		if attempt >= c.retry.MaxAttempts || !retryable(method, resp.StatusCode) {
+			return apiErr
+		}
+		if err := c.retry.wait(ctx, attempt, resp.Header.Get("Retry-After")); err != nil {
+			return apiErr
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Code generated by go run ./internal/gen; DO NOT EDIT.
+// Source: api/openapi/openapi.yaml, operations tagged "employees".
+//
+// Not generated, as they do not answer JSON: streamEmployeeEvents (text/event-stream).
+
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// basePath is where the spec mounts the API.
+const basePath = "/api/v1"
+
+// Employee is #/components/schemas/Employee in the spec.
+type Employee struct {
+	Attributes map[string]any `json:"attributes,omitempty"`
+	CreatedAt  time.Time      `json:"created_at"`
+	Email      string         `json:"email"`
+	ID         int64          `json:"id"`
+	ManagerID  *int64         `json:"manager_id,omitempty"`
+	Name       string         `json:"name"`
+	Position   string         `json:"position"`
+	Salary     float64        `json:"salary"`
+	UpdatedAt  time.Time      `json:"updated_at"`
+}
+
+// ListEmployeesParams are the query parameters of ListEmployees.
+type ListEmployeesParams struct {
+	// Fields: Comma separated fields to return, e.g. id,name,position; the id is always included.
+	Fields []string
+	// Attr sets the attr.<name> parameters, documented as attr.cost_center: Example custom attribute filter.
+	Attr map[string]string
+}
+
+func (p *ListEmployeesParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if len(p.Fields) > 0 {
+		query.Set("fields", strings.Join(p.Fields, ","))
+	}
+	for name, value := range p.Attr {
+		query.Set("attr."+name, value)
+	}
+	return query
+}
+
+// ListEmployees is GET /employees: Get All Employees.
+//
+// Get every employee, ordered by ID. Custom attributes can be filtered with
+// attr.<name>=<value>, fields selects the fields returned. Answers carry an
+// ETag and the last change of the returned employees as Last-Modified;
+// requests repeating them in If-None-Match or If-Modified-Since are answered
+// with 304 when nothing changed.
+func (c *Client) ListEmployees(ctx context.Context, params *ListEmployeesParams) ([]Employee, error) {
+	var out []Employee
+	err := c.do(ctx, http.MethodGet, "/employees", params.values(), nil, &out)
+	return out, err
+}
+
+// CreateEmployee is POST /employees: Create new employee.
+//
+// Create a new employee. The ID and creation time are assigned by the API.
+func (c *Client) CreateEmployee(ctx context.Context, employee Employee) (*Employee, error) {
+	var out Employee
+	if err := c.do(ctx, http.MethodPost, "/employees", nil, employee, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetEmployeeParams are the query parameters of GetEmployee.
+type GetEmployeeParams struct {
+	// Fields: Comma separated fields to return, e.g. id,name,position; the id is always included.
+	Fields []string
+}
+
+func (p *GetEmployeeParams) values() url.Values {
+	query := url.Values{}
+	if p == nil {
+		return query
+	}
+	if len(p.Fields) > 0 {
+		query.Set("fields", strings.Join(p.Fields, ","))
+	}
+	return query
+}
+
+// GetEmployee is GET /employees/{employeeId}: Get Employee By ID.
+//
+// Get an employee by ID, fields selects the fields returned. Supports
+// conditional requests like the list.
+func (c *Client) GetEmployee(ctx context.Context, employeeID int64, params *GetEmployeeParams) (*Employee, error) {
+	var out Employee
+	if err := c.do(ctx, http.MethodGet, "/employees/"+strconv.FormatInt(employeeID, 10), params.values(), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateEmployee is PUT /employees/{employeeId}: Update employee.
+//
+// Replace every field of an employee.
+func (c *Client) UpdateEmployee(ctx context.Context, employeeID int64, employee Employee) (*Employee, error) {
+	var out Employee
+	if err := c.do(ctx, http.MethodPut, "/employees/"+strconv.FormatInt(employeeID, 10), nil, employee, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteEmployee is DELETE /employees/{employeeId}: Delete employee.
+//
+// Delete an employee together with their attachments.
+func (c *Client) DeleteEmployee(ctx context.Context, employeeID int64) error {
+	return c.do(ctx, http.MethodDelete, "/employees/"+strconv.FormatInt(employeeID, 10), nil, nil, nil)
+}
@@ -0,0 +1,53 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxErrorBody caps how much of an error answer is kept.
+const maxErrorBody = 64 << 10
+
+// APIError is a non-2xx answer of the API.
+type APIError struct {
+	StatusCode int
+	// Message is the "error" field of the body, or the detail of a problem
+	// details answer; empty when the body is neither.
+	Message string
+	// Body is the raw answer, up to 64 KiB.
+	Body   []byte
+	Header http.Header
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+
+	e := &APIError{StatusCode: resp.StatusCode, Body: body, Header: resp.Header}
+	var envelope struct {
+		Error  string `json:"error"`
+		Detail string `json:"detail"`
+	}
+	if json.Unmarshal(body, &envelope) == nil {
+		e.Message = envelope.Error
+		if e.Message == "" {
+			e.Message = envelope.Detail
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("API answered %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return fmt.Sprintf("API answered %d: %s", e.StatusCode, e.Message)
+}
+
+// NotFound reports whether the API answered 404.
+func (e *APIError) NotFound() bool { return e.StatusCode == http.StatusNotFound }
+
+// Conflict reports whether the API answered 409, e.g. for a taken email.
+func (e *APIError) Conflict() bool { return e.StatusCode == http.StatusConflict }
@@ -0,0 +1,6 @@
+package client
+
+// employees.gen.go is generated from api/openapi/openapi.yaml. After changing
+// the document run go generate; go test ./pkg/client and
+// go run ./pkg/client/internal/gen -check fail while the two are out of sync.
+//go:generate go run ./internal/gen
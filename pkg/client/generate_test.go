@@ -0,0 +1,25 @@
+package client
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/pkg/client/internal/codegen"
+)
+
+// TestGeneratedClientIsCurrent fails when employees.gen.go is not what the
+// OpenAPI document generates, i.e. the spec changed without go generate.
+func TestGeneratedClientIsCurrent(t *testing.T) {
+	want, err := codegen.Generate("../../api/openapi/openapi.yaml", "employees", "api/openapi/openapi.yaml")
+	if err != nil {
+		t.Fatalf("generate client: %v", err)
+	}
+	got, err := os.ReadFile("employees.gen.go")
+	if err != nil {
+		t.Fatalf("read generated client: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatal("employees.gen.go is out of date with api/openapi/openapi.yaml, run go generate ./pkg/client")
+	}
+}
@@ -0,0 +1,530 @@
+// Package codegen generates the Go client in pkg/client from the OpenAPI
+// document. It is shared by the gen command and the drift test of the
+// client.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+var methods = []string{"get", "post", "put", "patch", "delete"}
+
+// generator turns the operations of one tag into client methods and the
+// component schemas they use into structs.
+type generator struct {
+	spec *spec
+	tag  string
+
+	buf     bytes.Buffer
+	types   map[string]string // schema name -> Go name
+	pending []string          // schemas still to emit
+	imports map[string]bool
+	skipped []string
+}
+
+// Generate reads the OpenAPI document at specPath and returns the formatted
+// client source for the operations tagged tag. source is the path of the
+// document named in the header of the file.
+func Generate(specPath, tag, source string) ([]byte, error) {
+	s, err := loadSpec(specPath)
+	if err != nil {
+		return nil, err
+	}
+	return generate(s, tag, source)
+}
+
+func generate(s *spec, tag, source string) ([]byte, error) {
+	g := &generator{
+		spec:    s,
+		tag:     tag,
+		types:   map[string]string{},
+		imports: map[string]bool{"context": true, "net/http": true},
+	}
+
+	if err := g.operations(); err != nil {
+		return nil, err
+	}
+	ops := g.buf.Bytes()
+
+	g.buf = bytes.Buffer{}
+	if err := g.definitions(); err != nil {
+		return nil, err
+	}
+	defs := g.buf.Bytes()
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by go run ./internal/gen; DO NOT EDIT.\n")
+	fmt.Fprintf(&out, "// Source: %s, operations tagged %q.\n", source, tag)
+	if len(g.skipped) > 0 {
+		fmt.Fprintf(&out, "//\n// Not generated, as they do not answer JSON: %s.\n", strings.Join(g.skipped, ", "))
+	}
+	fmt.Fprintf(&out, "\npackage client\n\nimport (\n")
+	imports := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		imports = append(imports, path)
+	}
+	sort.Strings(imports)
+	for _, path := range imports {
+		fmt.Fprintf(&out, "\t%q\n", path)
+	}
+	fmt.Fprintf(&out, ")\n\n")
+	fmt.Fprintf(&out, "// basePath is where the spec mounts the API.\nconst basePath = %q\n\n", s.basePath())
+	out.Write(defs)
+	out.Write(ops)
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("generated code does not compile: %w\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+func (g *generator) operations() error {
+	paths := make([]string, 0, len(g.spec.Paths))
+	for path := range g.spec.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	seen := map[string]string{}
+	for _, path := range paths {
+		item := g.spec.Paths[path]
+		for _, method := range methods {
+			op := item.operation(method)
+			if op == nil || !slices.Contains(op.Tags, g.tag) {
+				continue
+			}
+			where := strings.ToUpper(method) + " " + path
+			if op.OperationID == "" {
+				return fmt.Errorf("%s has no operationId", where)
+			}
+			if prev, ok := seen[op.OperationID]; ok {
+				return fmt.Errorf("%s and %s share the operationId %s", prev, where, op.OperationID)
+			}
+			seen[op.OperationID] = where
+			produces, err := g.produces(op)
+			if err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+			if len(produces) > 0 && !slices.Contains(produces, "application/json") {
+				g.skipped = append(g.skipped, fmt.Sprintf("%s (%s)", op.OperationID, strings.Join(produces, ", ")))
+				continue
+			}
+			if err := g.operation(method, path, append(slices.Clone(item.Parameters), op.Parameters...), op); err != nil {
+				return fmt.Errorf("%s: %w", where, err)
+			}
+		}
+	}
+	return nil
+}
+
+// produces lists the content types of the successful answers of op.
+func (g *generator) produces(op *operation) ([]string, error) {
+	var types []string
+	for code, r := range op.Responses {
+		if !strings.HasPrefix(code, "2") {
+			continue
+		}
+		r, err := g.spec.response(r)
+		if err != nil {
+			return nil, err
+		}
+		for contentType := range r.Content {
+			if !slices.Contains(types, contentType) {
+				types = append(types, contentType)
+			}
+		}
+	}
+	sort.Strings(types)
+	return types, nil
+}
+
+// operation emits the method of op. params are the parameters of the path
+// followed by those of the operation.
+func (g *generator) operation(method, path string, params []parameter, op *operation) error {
+	name := goName(op.OperationID)
+
+	var (
+		args   []string
+		query  []parameter
+		pathGo = fmt.Sprintf("%q", path)
+	)
+	for _, p := range params {
+		p, err := g.spec.parameter(p)
+		if err != nil {
+			return err
+		}
+		switch p.In {
+		case "path":
+			typ, err := g.paramType(p)
+			if err != nil {
+				return err
+			}
+			arg := varName(p.Name)
+			args = append(args, arg+" "+typ)
+			value := arg
+			switch typ {
+			case "int64":
+				g.imports["strconv"] = true
+				value = "strconv.FormatInt(" + arg + ", 10)"
+			case "string":
+				g.imports["net/url"] = true
+				value = "url.PathEscape(" + arg + ")"
+			default:
+				return fmt.Errorf("path parameter %s has unsupported type %s", p.Name, typ)
+			}
+			pathGo = strings.Replace(pathGo, "{"+p.Name+"}", `"+`+value+`+"`, 1)
+		case "query":
+			query = append(query, p)
+		default:
+			return fmt.Errorf("%s parameters like %s are not supported", p.In, p.Name)
+		}
+	}
+	pathGo = strings.TrimSuffix(strings.TrimPrefix(pathGo, `""+`), `+""`)
+
+	var body string
+	if op.RequestBody != nil {
+		media, ok := op.RequestBody.Content["application/json"]
+		if !ok {
+			return fmt.Errorf("only JSON request bodies are supported")
+		}
+		typ, err := g.typeOf(media.Schema)
+		if err != nil {
+			return err
+		}
+		body = "body"
+		if media.Schema.Ref != "" {
+			body = varName(typ)
+		}
+		args = append(args, body+" "+typ)
+	}
+	if len(query) > 0 {
+		if err := g.params(name, query); err != nil {
+			return err
+		}
+		args = append(args, "params *"+name+"Params")
+	}
+
+	result, err := g.result(op)
+	if err != nil {
+		return err
+	}
+
+	g.doc(fmt.Sprintf("%s is %s %s: %s.", name, strings.ToUpper(method), path, strings.TrimSuffix(op.Summary, ".")), op.Description)
+	returns := "error"
+	if result != "" {
+		returns = "(" + result + ", error)"
+	}
+	fmt.Fprintf(&g.buf, "func (c *Client) %s(%s) %s {\n", name, strings.Join(append([]string{"ctx context.Context"}, args...), ", "), returns)
+
+	queryArg := "nil"
+	if len(query) > 0 {
+		queryArg = "params.values()"
+	}
+	bodyArg := "nil"
+	if body != "" {
+		bodyArg = body
+	}
+	call := fmt.Sprintf("c.do(ctx, http.Method%s, %s, %s, %s", methodName(method), pathGo, queryArg, bodyArg)
+
+	switch {
+	case result == "":
+		fmt.Fprintf(&g.buf, "\treturn %s, nil)\n", call)
+	case strings.HasPrefix(result, "*"):
+		fmt.Fprintf(&g.buf, "\tvar out %s\n", result[1:])
+		fmt.Fprintf(&g.buf, "\tif err := %s, &out); err != nil {\n\t\treturn nil, err\n\t}\n\treturn &out, nil\n", call)
+	default:
+		fmt.Fprintf(&g.buf, "\tvar out %s\n", result)
+		fmt.Fprintf(&g.buf, "\terr := %s, &out)\n\treturn out, err\n", call)
+	}
+	fmt.Fprintf(&g.buf, "}\n\n")
+	return nil
+}
+
+// params emits the query parameter struct of an operation. A parameter
+// with a dot, like attr.cost_center, stands for a family of parameters
+// sharing the prefix and becomes a map. Arrays are sent comma separated, so
+// they need explode: false.
+func (g *generator) params(name string, query []parameter) error {
+	type field struct {
+		name, typ, key, doc, example string
+		prefix                       bool
+	}
+	var fields []field
+	seen := map[string]bool{}
+	for _, p := range query {
+		f := field{key: p.Name, doc: p.Description, example: p.Name}
+		if prefix, _, ok := strings.Cut(p.Name, "."); ok {
+			f.name, f.typ, f.key, f.prefix = goName(prefix), "map[string]string", prefix+".", true
+		} else {
+			typ, err := g.paramType(p)
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(typ, "[]") && (p.Explode == nil || *p.Explode) {
+				return fmt.Errorf("query parameter %s: only arrays with explode: false are supported", p.Name)
+			}
+			f.name, f.typ = goName(p.Name), typ
+		}
+		if seen[f.name] {
+			continue
+		}
+		seen[f.name] = true
+		fields = append(fields, f)
+	}
+
+	g.imports["net/url"] = true
+	fmt.Fprintf(&g.buf, "// %sParams are the query parameters of %s.\ntype %sParams struct {\n", name, name, name)
+	for _, f := range fields {
+		if f.prefix {
+			fmt.Fprintf(&g.buf, "\t// %s sets the %s<name> parameters, documented as %s: %s.\n", f.name, f.key, f.example, strings.TrimSuffix(f.doc, "."))
+		} else if f.doc != "" {
+			fmt.Fprintf(&g.buf, "\t// %s: %s.\n", f.name, strings.TrimSuffix(f.doc, "."))
+		}
+		fmt.Fprintf(&g.buf, "\t%s %s\n", f.name, f.typ)
+	}
+	fmt.Fprintf(&g.buf, "}\n\n")
+
+	fmt.Fprintf(&g.buf, "func (p *%sParams) values() url.Values {\n\tquery := url.Values{}\n\tif p == nil {\n\t\treturn query\n\t}\n", name)
+	for _, f := range fields {
+		switch {
+		case f.prefix:
+			fmt.Fprintf(&g.buf, "\tfor name, value := range p.%s {\n\t\tquery.Set(%q+name, value)\n\t}\n", f.name, f.key)
+		case f.typ == "string":
+			fmt.Fprintf(&g.buf, "\tif p.%s != \"\" {\n\t\tquery.Set(%q, p.%s)\n\t}\n", f.name, f.key, f.name)
+		case f.typ == "int64":
+			g.imports["strconv"] = true
+			fmt.Fprintf(&g.buf, "\tif p.%s != 0 {\n\t\tquery.Set(%q, strconv.FormatInt(p.%s, 10))\n\t}\n", f.name, f.key, f.name)
+		case f.typ == "bool":
+			fmt.Fprintf(&g.buf, "\tif p.%s {\n\t\tquery.Set(%q, \"true\")\n\t}\n", f.name, f.key)
+		case f.typ == "[]string":
+			g.imports["strings"] = true
+			fmt.Fprintf(&g.buf, "\tif len(p.%s) > 0 {\n\t\tquery.Set(%q, strings.Join(p.%s, \",\"))\n\t}\n", f.name, f.key, f.name)
+		default:
+			return fmt.Errorf("query parameter %s has unsupported type %s", f.key, f.typ)
+		}
+	}
+	fmt.Fprintf(&g.buf, "\treturn query\n}\n\n")
+	return nil
+}
+
+// result is the Go type of the first 2xx response that has a JSON schema;
+// primitive answers like "deleted successfully" are dropped.
+func (g *generator) result(op *operation) (string, error) {
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		if strings.HasPrefix(code, "2") {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		r, err := g.spec.response(op.Responses[code])
+		if err != nil {
+			return "", err
+		}
+		s := r.Content["application/json"].Schema
+		switch {
+		case s == nil:
+			continue
+		case s.Ref != "":
+			typ, err := g.typeOf(s)
+			return "*" + typ, err
+		case s.Type.primary() == "array":
+			return g.typeOf(s)
+		}
+	}
+	return "", nil
+}
+
+func (g *generator) paramType(p parameter) (string, error) {
+	return g.typeOf(p.Schema)
+}
+
+func (g *generator) typeOf(s *schema) (string, error) {
+	if s == nil {
+		return "", fmt.Errorf("missing schema")
+	}
+	if s.Ref != "" {
+		def, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+		if !ok {
+			return "", fmt.Errorf("unsupported reference %s", s.Ref)
+		}
+		return g.definition(def)
+	}
+	switch s.Type.primary() {
+	case "string":
+		if s.Format == "date-time" {
+			g.imports["time"] = true
+			return "time.Time", nil
+		}
+		return "string", nil
+	case "integer":
+		return "int64", nil
+	case "number":
+		return "float64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		item, err := g.typeOf(s.Items)
+		return "[]" + item, err
+	case "object":
+		if s.Properties != nil {
+			return "", fmt.Errorf("inline objects are not supported, use a component schema")
+		}
+		if value, ok := s.AdditionalProperties.(map[string]any); ok && len(value) > 0 {
+			if t, _ := value["type"].(string); t != "" {
+				elem, err := g.typeOf(&schema{Type: types{t}})
+				return "map[string]" + elem, err
+			}
+		}
+		return "map[string]any", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %q", s.Type.primary())
+}
+
+// definition names a component schema and queues it for emission. Schemas
+// that are not objects, like ID, are used as the type they describe.
+func (g *generator) definition(def string) (string, error) {
+	if name, ok := g.types[def]; ok {
+		return name, nil
+	}
+	s, ok := g.spec.Components.Schemas[def]
+	if !ok {
+		return "", fmt.Errorf("unknown schema %s", def)
+	}
+	if s.Type.primary() != "object" {
+		return g.typeOf(s)
+	}
+	name := goName(def)
+	for other, taken := range g.types {
+		if taken == name {
+			return "", fmt.Errorf("schemas %s and %s would both be %s", other, def, name)
+		}
+	}
+	g.types[def] = name
+	g.pending = append(g.pending, def)
+	return name, nil
+}
+
+func (g *generator) definitions() error {
+	for i := 0; i < len(g.pending); i++ {
+		def := g.pending[i]
+		s := g.spec.Components.Schemas[def]
+		name := g.types[def]
+
+		props := make([]string, 0, len(s.Properties))
+		for prop := range s.Properties {
+			props = append(props, prop)
+		}
+		sort.Strings(props)
+
+		fmt.Fprintf(&g.buf, "// %s is #/components/schemas/%s in the spec.\ntype %s struct {\n", name, def, name)
+		for _, prop := range props {
+			p := s.Properties[prop]
+			typ, err := g.typeOf(p)
+			if err != nil {
+				return fmt.Errorf("%s.%s: %w", def, prop, err)
+			}
+			tag := prop
+			switch {
+			case p.Type.nullable():
+				typ, tag = "*"+typ, tag+",omitempty"
+			case strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["):
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", goName(prop), typ, tag)
+		}
+		fmt.Fprintf(&g.buf, "}\n\n")
+	}
+	return nil
+}
+
+func (g *generator) doc(summary, description string) {
+	fmt.Fprintf(&g.buf, "// %s\n", summary)
+	if description != "" {
+		fmt.Fprintf(&g.buf, "//\n")
+		for _, line := range wrap(description, 74) {
+			fmt.Fprintf(&g.buf, "// %s\n", line)
+		}
+	}
+}
+
+func wrap(text string, width int) []string {
+	var (
+		lines []string
+		line  string
+	)
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+var initialisms = map[string]string{"id": "ID", "url": "URL", "api": "API", "http": "HTTP", "json": "JSON", "sha": "SHA"}
+
+// goName turns snake_case, kebab-case and camelCase into an exported Go
+// name: manager_id and managerId both become ManagerID.
+func goName(s string) string {
+	var (
+		words []string
+		word  []rune
+	)
+	for _, r := range s {
+		switch {
+		case r == '_' || r == '-' || r == '.' || r == ' ':
+			words, word = append(words, string(word)), nil
+		case unicode.IsUpper(r) && len(word) > 0 && unicode.IsLower(word[len(word)-1]):
+			words, word = append(words, string(word)), []rune{r}
+		default:
+			word = append(word, r)
+		}
+	}
+	words = append(words, string(word))
+
+	var b strings.Builder
+	for _, w := range words {
+		if w == "" {
+			continue
+		}
+		if up, ok := initialisms[strings.ToLower(w)]; ok {
+			b.WriteString(up)
+			continue
+		}
+		b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+	}
+	return b.String()
+}
+
+func varName(s string) string {
+	name := goName(s)
+	for i, r := range name {
+		if !unicode.IsUpper(r) {
+			if i > 1 {
+				i--
+			}
+			return strings.ToLower(name[:i]) + name[i:]
+		}
+	}
+	return strings.ToLower(name)
+}
+
+func methodName(method string) string {
+	return strings.ToUpper(method[:1]) + method[1:]
+}
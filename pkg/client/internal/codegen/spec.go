@@ -0,0 +1,170 @@
+package codegen
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// spec is the part of an OpenAPI 3.1 document the generator reads.
+type spec struct {
+	Servers    []server            `yaml:"servers"`
+	Paths      map[string]pathItem `yaml:"paths"`
+	Components components          `yaml:"components"`
+}
+
+type server struct {
+	URL string `yaml:"url"`
+}
+
+type components struct {
+	Schemas    map[string]*schema   `yaml:"schemas"`
+	Parameters map[string]parameter `yaml:"parameters"`
+	Responses  map[string]response  `yaml:"responses"`
+}
+
+type pathItem struct {
+	Parameters []parameter `yaml:"parameters"`
+	Get        *operation  `yaml:"get"`
+	Post       *operation  `yaml:"post"`
+	Put        *operation  `yaml:"put"`
+	Patch      *operation  `yaml:"patch"`
+	Delete     *operation  `yaml:"delete"`
+}
+
+func (p pathItem) operation(method string) *operation {
+	switch method {
+	case "get":
+		return p.Get
+	case "post":
+		return p.Post
+	case "put":
+		return p.Put
+	case "patch":
+		return p.Patch
+	case "delete":
+		return p.Delete
+	}
+	return nil
+}
+
+type operation struct {
+	OperationID string              `yaml:"operationId"`
+	Summary     string              `yaml:"summary"`
+	Description string              `yaml:"description"`
+	Tags        []string            `yaml:"tags"`
+	Parameters  []parameter         `yaml:"parameters"`
+	RequestBody *requestBody        `yaml:"requestBody"`
+	Responses   map[string]response `yaml:"responses"`
+}
+
+type parameter struct {
+	Ref         string  `yaml:"$ref"`
+	Name        string  `yaml:"name"`
+	In          string  `yaml:"in"`
+	Description string  `yaml:"description"`
+	Required    bool    `yaml:"required"`
+	Explode     *bool   `yaml:"explode"`
+	Schema      *schema `yaml:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `yaml:"required"`
+	Content  map[string]mediaType `yaml:"content"`
+}
+
+type response struct {
+	Ref         string               `yaml:"$ref"`
+	Description string               `yaml:"description"`
+	Content     map[string]mediaType `yaml:"content"`
+}
+
+type mediaType struct {
+	Schema *schema `yaml:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `yaml:"$ref"`
+	Type                 types              `yaml:"type"`
+	Format               string             `yaml:"format"`
+	Items                *schema            `yaml:"items"`
+	Properties           map[string]*schema `yaml:"properties"`
+	AdditionalProperties any                `yaml:"additionalProperties"`
+}
+
+// types is the type of a schema, which OpenAPI 3.1 allows to be a list:
+// [integer, "null"] is a nullable integer.
+type types []string
+
+func (t *types) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = types{node.Value}
+		return nil
+	}
+	return node.Decode((*[]string)(t))
+}
+
+// primary is the type without "null".
+func (t types) primary() string {
+	for _, typ := range t {
+		if typ != "null" {
+			return typ
+		}
+	}
+	return ""
+}
+
+func (t types) nullable() bool { return slices.Contains(t, "null") }
+
+func loadSpec(path string) (*spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s spec
+	if err := yaml.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("cannot parse %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// basePath is where the first server mounts the API.
+func (s *spec) basePath() string {
+	if len(s.Servers) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(s.Servers[0].URL, "/")
+}
+
+func (s *spec) parameter(p parameter) (parameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	name, ok := strings.CutPrefix(p.Ref, "#/components/parameters/")
+	if !ok {
+		return p, fmt.Errorf("unsupported reference %s", p.Ref)
+	}
+	resolved, ok := s.Components.Parameters[name]
+	if !ok {
+		return p, fmt.Errorf("unknown parameter %s", p.Ref)
+	}
+	return resolved, nil
+}
+
+func (s *spec) response(r response) (response, error) {
+	if r.Ref == "" {
+		return r, nil
+	}
+	name, ok := strings.CutPrefix(r.Ref, "#/components/responses/")
+	if !ok {
+		return r, fmt.Errorf("unsupported reference %s", r.Ref)
+	}
+	resolved, ok := s.Components.Responses[name]
+	if !ok {
+		return r, fmt.Errorf("unknown response %s", r.Ref)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,82 @@
+// Command gen writes pkg/client/employees.gen.go from api/openapi/openapi.yaml.
+//
+// Usage:
+//
+//	go generate ./pkg/client          # regenerate after changing the spec
+//	go run ./pkg/client/internal/gen -check
+//
+// -check writes nothing and exits 1 when the committed file differs from
+// what the spec generates. go test ./pkg/client runs the same comparison.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MaulanaAhmadSulami/juke_test.git/pkg/client/internal/codegen"
+)
+
+func main() {
+	root, err := moduleRoot()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+
+	specPath := flag.String("spec", filepath.Join(root, "api", "openapi", "openapi.yaml"), "OpenAPI document to read")
+	out := flag.String("out", filepath.Join(root, "pkg", "client", "employees.gen.go"), "file to write")
+	tag := flag.String("tag", "employees", "generate the operations with this tag")
+	check := flag.Bool("check", false, "only report whether -out is up to date")
+	flag.Parse()
+
+	source, err := filepath.Rel(root, *specPath)
+	if err != nil {
+		source = *specPath
+	}
+	src, err := codegen.Generate(*specPath, *tag, filepath.ToSlash(source))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+
+	if *check {
+		current, err := os.ReadFile(*out)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			fmt.Fprintln(os.Stderr, "gen:", err)
+			os.Exit(1)
+		}
+		if !bytes.Equal(current, src) {
+			fmt.Fprintf(os.Stderr, "gen: %s is out of date with %s, run go generate ./pkg/client\n", *out, source)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := os.WriteFile(*out, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+// moduleRoot finds the directory holding go.mod, so the defaults work from
+// go generate as well as from the repository root.
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, "go.mod")); err == nil {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("go.mod not found")
+		}
+		dir = parent
+	}
+}
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy retries answers that are likely to succeed later: 429 for
+// every request, other 5xx only for requests that are safe to repeat. A
+// POST is not retried on 500, 502 or 504 as the employee may already have
+// been created; 503 means the API did not take it.
+type RetryPolicy struct {
+	// MaxAttempts counts the first try, 0 and 1 disable retries.
+	MaxAttempts int
+	// MinBackoff doubles on every retry up to MaxBackoff, with jitter.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy tries four times, waiting at most 1.4s in between.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 4,
+	MinBackoff:  200 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+var errRetryTooLate = errors.New("retry-after exceeds the maximum backoff")
+
+func retryable(method string, status int) bool {
+	switch {
+	case status == http.StatusTooManyRequests, status == http.StatusServiceUnavailable:
+		return true
+	case status >= 500:
+		return method != http.MethodPost && method != http.MethodPatch
+	}
+	return false
+}
+
+// wait sleeps before the next attempt. A Retry-After header replaces the
+// backoff; when it asks for more than MaxBackoff the call gives up instead.
+func (p RetryPolicy) wait(ctx context.Context, attempt int, retryAfter string) error {
+	delay := p.MinBackoff << (attempt - 1)
+	if delay <= 0 || delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	delay = delay/2 + rand.N(delay/2+1)
+	if after, ok := parseRetryAfter(retryAfter); ok {
+		if after > p.MaxBackoff {
+			return errRetryTooLate
+		}
+		delay = after
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func parseRetryAfter(v string) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(v); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(time.Until(at), 0), true
+	}
+	return 0, false
+}